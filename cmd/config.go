@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"socialservice/conf"
+)
+
+// configCmd runs `config print`, which dumps the effective configuration,
+// after every source and flag is applied, with secrets masked.
+func configCmd(args []string, c *conf.Conf, err error) {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "usage: socialservice [flags] config print")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	out, err := yaml.Marshal(c.Masked())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	_, _ = os.Stdout.Write(out)
+}
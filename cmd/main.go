@@ -1,44 +1,133 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"github.com/micro/go-micro"
 	"github.com/micro/go-micro/registry"
 	"github.com/micro/go-micro/registry/etcd"
+	mserver "github.com/micro/go-micro/server"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"os/signal"
 	"socialservice/conf"
-	"socialservice/global"
 	"socialservice/rpc/social/pb"
 	"socialservice/server"
-)
-
-var (
-	socialConf *conf.Conf
-	err        error
+	"socialservice/util/concurrent"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
+	"socialservice/util/tracing"
+	"syscall"
+	"time"
 )
 
 func main() {
-	socialConf, err = conf.LoadYaml(conf.SocialConfPath)
-	if err != nil {
-		panic(err)
-	}
+	configPath := flag.String("config", conf.ConfigPath(), "YAML config file, defaults to $"+conf.ConfigPathEnv)
+	flags := conf.NewFlagSource(flag.CommandLine)
+	flag.Parse()
 
-	global.InfoLog, err = conf.InitLog(socialConf.LogPath.Info)
+	loader, socialConf, err := conf.LoadSocial(*configPath, flags)
+	if flag.Arg(0) == "config" {
+		configCmd(flag.Args()[1:], socialConf, err)
+		return
+	}
 	if err != nil {
 		panic(err)
 	}
-	global.ExcLog, err = conf.InitLog(socialConf.LogPath.Exc)
+
+	err = logger.Init(socialConf.LogPath)
 	if err != nil {
 		panic(err)
 	}
-	global.DebugLog, err = conf.InitLog(socialConf.LogPath.Debug)
+	lifecycle.OnStop("logger", func(ctx context.Context) error {
+		return logger.Sync()
+	})
+	concurrent.OnPanic = metrics.Panic
+	concurrent.OnPoolReject = metrics.PoolRejected
+
+	shutdownTracing, err := tracing.Init(socialConf.Grpc.Name, socialConf.Trace.Exporter, socialConf.Trace.Endpoint, socialConf.Trace.Ratio)
 	if err != nil {
 		panic(err)
 	}
+	lifecycle.OnStop("tracing", shutdownTracing)
 
 	err = server.InitService(socialConf)
 	if err != nil {
 		panic(err)
 	}
 
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	lifecycle.OnStop("config watch", func(ctx context.Context) error {
+		stopWatch()
+		return loader.Close()
+	})
+	loader.Subscribe(server.UpdateConfig)
+	loader.Watch(watchCtx, func(err error) {
+		logger.Error(watchCtx, "config watch", logger.Err(err))
+	})
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", metrics.Handler())
+	adminMux.Handle("/log/level", logger.LevelHandler())
+	adminMux.HandleFunc("/healthz", server.Healthz)
+	adminMux.HandleFunc("/readyz", server.Readyz)
+	adminServer := &http.Server{Addr: socialConf.Admin.Addr, Handler: adminMux}
+	lifecycle.OnStop("admin server", adminServer.Shutdown)
+	concurrent.Go(func() {
+		err := adminServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			logger.Error(context.Background(), "admin server", zap.String("addr", socialConf.Admin.Addr), logger.Err(err))
+		}
+	})
+
+	if socialConf.Health.GRPCAddr != "" {
+		concurrent.Go(func() {
+			err := server.ServeHealthGRPC(socialConf.Health.GRPCAddr)
+			if err != nil {
+				logger.Error(context.Background(), "grpc health server", zap.String("addr", socialConf.Health.GRPCAddr), logger.Err(err))
+			}
+		})
+	}
+
+	if socialConf.Gateway.Addr != "" {
+		concurrent.Go(func() {
+			err := server.ServeGateway(socialConf.Gateway)
+			if err != nil {
+				logger.Error(context.Background(), "gateway", zap.String("addr", socialConf.Gateway.Addr), logger.Err(err))
+			}
+		})
+	}
+
+	shutdownTimeout := time.Duration(socialConf.ShutdownTimeout) * time.Second
+	if shutdownTimeout <= 0 {
+		shutdownTimeout = lifecycle.DefaultShutdownTimeout
+	}
+
+	if socialConf.Serves(conf.TransportGRPC) {
+		concurrent.Go(func() {
+			err := server.ServeGRPC(socialConf.GRPCServer)
+			if err != nil {
+				logger.Error(context.Background(), "grpc server", zap.String("addr", socialConf.GRPCServer.Addr), logger.Err(err))
+			}
+		})
+	}
+	if !socialConf.Serves(conf.TransportMicro) {
+		// go-micro is what waits for the signal otherwise
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT)
+		<-sig
+		server.Drain()
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		err = lifecycle.Shutdown(ctx)
+		if err != nil {
+			panic(err)
+		}
+		return
+	}
+
 	etcdRegistry := etcd.NewRegistry(func(options *registry.Options) {
 		options.Addrs = socialConf.Etcd.Addr
 	})
@@ -46,9 +135,26 @@ func main() {
 	service := micro.NewService(
 		micro.Name(socialConf.Grpc.Name),
 		micro.Address(socialConf.Grpc.Addr),
-		micro.Registry(etcdRegistry),
+		micro.Registry(server.ReadyRegistry(etcdRegistry)),
+		micro.RegisterInterval(server.HealthInterval()),
+		func(o *micro.Options) {
+			o.Server.Init(mserver.RegisterCheck(server.ReadyCheck))
+		},
+		// stop failing readiness first, then let go-micro deregister and
+		// wait for in-flight requests before background work is drained
+		micro.BeforeStop(func() error {
+			server.Drain()
+			return nil
+		}),
+		micro.AfterStop(func() error {
+			ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			return lifecycle.Shutdown(ctx)
+		}),
+		micro.WrapHandler(tracing.HandlerWrapper, logger.HandlerWrapper, metrics.HandlerWrapper),
 	)
-	service.Init()
+	// no service.Init(): the command line belongs to the flags above and
+	// go-micro's own parser would reject them
 	err = social_service.RegisterSocialServerHandler(
 		service.Server(),
 		new(server.SocialService),
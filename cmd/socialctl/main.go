@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"socialservice/conf"
+	"socialservice/server"
+	"socialservice/util/lifecycle"
+	"strconv"
+	"time"
+)
+
+const usage = `usage: socialctl [flags] <command> <uid> [target_uid]
+
+commands:
+  follows <uid>              list the users uid follows
+  followers <uid>            list the followers of uid
+  topics <uid>               list the topics uid follows
+  counts <uid>               show stored and served counters
+  follow <uid> <target>      make uid follow target
+  unfollow <uid> <target>    make uid unfollow target
+  rebuild <uid>              drop and refill the cached relations and counters
+  recount <uid>              recompute the counters from the relation tables
+  export <uid>               dump every relation of uid
+  snapshot <days_ago>        record the follower history of the UTC day days_ago before today
+  audit <uid>                list the newest follow changes of uid
+  restore <uid> <since>      bring back what uid unfollowed since a unix time
+  erase <uid>                delete every relation of uid, for account deletion
+
+flags:
+`
+
+// socialctl works on storage directly with the service's own functions,
+// so it needs the same config as the service, not a running instance.
+func main() {
+	configPath := flag.String("config", conf.ConfigPath(), "YAML config file, defaults to $"+conf.ConfigPathEnv)
+	format := flag.String("format", "json", "output format, json or csv")
+	flags := conf.NewFlagSource(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok || flag.NArg() != cmd.args+1 || (*format != "json" && *format != "csv") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ids := make([]int64, cmd.args)
+	for i := range ids {
+		id, err := strconv.ParseInt(flag.Arg(i+1), 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bad id %q\n", flag.Arg(i+1))
+			os.Exit(2)
+		}
+		ids[i] = id
+	}
+
+	loader, c, err := conf.LoadSocial(*configPath, flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	_ = loader.Close()
+	err = server.InitStorage(c)
+	if err == nil {
+		var out interface{}
+		out, err = cmd.run(context.Background(), ids)
+		if err == nil && out != nil {
+			err = write(*format, out)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lifecycle.DefaultShutdownTimeout)
+	defer cancel()
+	if serr := lifecycle.Shutdown(ctx); serr != nil && err == nil {
+		err = serr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+type command struct {
+	args int
+	run  func(ctx context.Context, ids []int64) (interface{}, error)
+}
+
+var commands = map[string]command{
+	"follows": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminFollows(ctx, ids[0])
+	}},
+	"followers": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminFollowers(ctx, ids[0])
+	}},
+	"topics": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminTopics(ctx, ids[0])
+	}},
+	"counts": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminCounts(ctx, ids[0])
+	}},
+	"follow": {2, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return nil, server.AdminFollow(ctx, ids[0], ids[1])
+	}},
+	"unfollow": {2, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return nil, server.AdminUnfollow(ctx, ids[0], ids[1])
+	}},
+	"rebuild": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return nil, server.RebuildCache(ctx, ids[0])
+	}},
+	"recount": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.Recount(ctx, ids[0])
+	}},
+	"export": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.ExportGraph(ctx, ids[0])
+	}},
+	"audit": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminAudit(ctx, ids[0])
+	}},
+	"restore": {2, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.AdminRestore(ctx, ids[0], time.Unix(ids[1], 0))
+	}},
+	"erase": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		return server.EraseUserGraph(ctx, ids[0])
+	}},
+	"snapshot": {1, func(ctx context.Context, ids []int64) (interface{}, error) {
+		day := time.Now().UTC().AddDate(0, 0, -int(ids[0]))
+		n, err := server.SnapshotFollowerHistory(ctx, day)
+		if err == nil {
+			fmt.Fprintf(os.Stderr, "recorded %v users for %v\n", n, day.Format("2006-01-02"))
+		}
+		return nil, err
+	}},
+}
+
+func write(format string, out interface{}) error {
+	if format == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	w := csv.NewWriter(os.Stdout)
+	switch v := out.(type) {
+	case []server.Edge:
+		_ = w.Write([]string{"uid", "target_id", "ctime"})
+		writeEdges(w, "", v)
+	case *server.Graph:
+		_ = w.Write([]string{"relation", "uid", "target_id", "ctime"})
+		writeEdges(w, "follow", v.Follows)
+		writeEdges(w, "follower", v.Followers)
+		writeEdges(w, "topic", v.Topics)
+	case *server.CountReport:
+		_ = w.Write([]string{"source", "follow_count", "follower_count", "follow_topic_count"})
+		writeCounts(w, "stored", v.Stored)
+		writeCounts(w, "served", v.Served)
+	case server.Counts:
+		_ = w.Write([]string{"follow_count", "follower_count", "follow_topic_count"})
+		writeCounts(w, "", v)
+	case []server.FollowAudit:
+		_ = w.Write([]string{"id", "uid", "target_id", "follow_type", "action", "source", "ctime"})
+		for _, a := range v {
+			_ = w.Write([]string{strconv.FormatInt(a.ID, 10), strconv.FormatInt(a.UID, 10), strconv.FormatInt(a.TargetID, 10),
+				strconv.Itoa(int(a.FollowType)), a.Action, a.Source, strconv.FormatInt(a.Ctime.Unix(), 10)})
+		}
+	case *server.GraphDeletion:
+		_ = w.Write([]string{"uid", "done", "follows", "followers", "topics", "ctime", "mtime"})
+		_ = w.Write([]string{strconv.FormatInt(v.UID, 10), strconv.FormatBool(v.Done), strconv.FormatInt(v.Follows, 10),
+			strconv.FormatInt(v.Followers, 10), strconv.FormatInt(v.Topics, 10), strconv.FormatInt(v.Ctime.Unix(), 10), strconv.FormatInt(v.Mtime.Unix(), 10)})
+	case *server.RestoreReport:
+		_ = w.Write([]string{"relation", "target_id"})
+		for _, id := range v.Follows {
+			_ = w.Write([]string{"follow", strconv.FormatInt(id, 10)})
+		}
+		for _, id := range v.Topics {
+			_ = w.Write([]string{"topic", strconv.FormatInt(id, 10)})
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeEdges writes one row per edge, prefixed by relation when it is set,
+// so an export of several relations stays a single table.
+func writeEdges(w *csv.Writer, relation string, edges []server.Edge) {
+	for _, e := range edges {
+		row := []string{strconv.FormatInt(e.UID, 10), strconv.FormatInt(e.TargetID, 10), strconv.FormatInt(e.Ctime, 10)}
+		if relation != "" {
+			row = append([]string{relation}, row...)
+		}
+		_ = w.Write(row)
+	}
+}
+
+func writeCounts(w *csv.Writer, source string, c server.Counts) {
+	row := []string{strconv.FormatInt(c.FollowCount, 10), strconv.FormatInt(c.FollowerCount, 10), strconv.FormatInt(c.FollowTopicCount, 10)}
+	if source != "" {
+		row = append([]string{source}, row...)
+	}
+	_ = w.Write(row)
+}
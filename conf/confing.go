@@ -1,13 +1,12 @@
 package conf
 
 import (
+	"fmt"
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/go-redis/redis/v8"
 	"github.com/jinzhu/gorm"
 	"gopkg.in/yaml.v2"
 	"io/ioutil"
-	"log"
-	"os"
 	"time"
 	"upper.io/db.v3/lib/sqlbuilder"
 	"upper.io/db.v3/mysql"
@@ -25,14 +24,28 @@ const (
 	FeedConfPath    = "/home/work/zzlove/conf/zzlove/feed.yaml"
 
 	MysqlAddr = "%v:%v@tcp(%v:%v)/%v?charset=utf8mb4&parseTime=True"
+
+	DefaultMysqlMaxOpen     = 50
+	DefaultMysqlMaxIdle     = 10
+	DefaultMysqlMaxLifetime = 3 * time.Minute
+
+	TransportMicro = "micro"
+	TransportGRPC  = "grpc"
 )
 
 type MysqlConf struct {
-	Host     string `yaml:"host"`
-	Port     int    `yaml:"port"`
-	DB       string `yaml:"db"`
-	User     string `yaml:"user"`
-	Password string `yaml:"password"`
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"`
+	DB          string `yaml:"db"`
+	User        string `yaml:"user"`
+	Password    string `yaml:"password" secret:"true"`
+	MaxOpen     int    `yaml:"max_open"`
+	MaxIdle     int    `yaml:"max_idle"`
+	MaxLifetime int    `yaml:"max_lifetime"` // seconds
+}
+
+func (c MysqlConf) DSN() string {
+	return fmt.Sprintf(MysqlAddr, c.User, c.Password, c.Host, c.Port, c.DB)
 }
 
 type RedisConf struct {
@@ -53,29 +66,134 @@ type GrpcConf struct {
 	Name string `yaml:"name"`
 }
 
+// GRPCServerConf is the native gRPC transport, next to or instead of the
+// go-micro one configured in GrpcConf.
+type GRPCServerConf struct {
+	Addr       string   `yaml:"addr"`
+	Tokens     []string `yaml:"tokens" secret:"true"` // accepted bearer tokens, empty disables auth
+	Reflection bool     `yaml:"reflection"`
+}
+
 type EtcdConf struct {
-	Addr []string `yaml:"addr"`
+	Addr      []string `yaml:"addr"`
+	ConfigKey string   `yaml:"config_key"` // YAML overlay watched for hot reload, empty disables it
 }
 
 type KafkaConf struct {
 	Addr []string `yaml:"addr"`
 }
 
+type ReplicaConf struct {
+	Policy   string `yaml:"policy"`   // round_robin | least_conn
+	MaxLag   int    `yaml:"max_lag"`  // seconds
+	Interval int    `yaml:"interval"` // seconds
+}
+
+type LocalCacheConf struct {
+	Size int `yaml:"size"`
+	TTL  int `yaml:"ttl"` // seconds
+}
+
+type PoolConf struct {
+	Workers  int    `yaml:"workers"`
+	Queue    int    `yaml:"queue"`
+	Overflow string `yaml:"overflow"` // drop | block | caller_runs
+	Timeout  int    `yaml:"timeout"`  // seconds per task, 0 for none
+}
+
+type AdminConf struct {
+	Addr   string   `yaml:"addr"`
+	Tokens []string `yaml:"tokens" secret:"true"` // bearer tokens for the admin RPCs, empty refuses them
+}
+
+type GatewayConf struct {
+	Addr        string   `yaml:"addr"`                 // HTTP/JSON gateway, empty disables it
+	ReadTimeout int      `yaml:"read_timeout"`         // seconds
+	Tokens      []string `yaml:"tokens" secret:"true"` // accepted bearer tokens, empty disables auth
+}
+
+type HistoryConf struct {
+	SnapshotAt string `yaml:"snapshot_at"` // HH:MM UTC to record the day before, empty disables the job
+}
+
+type HealthConf struct {
+	Interval int    `yaml:"interval"`  // seconds
+	GRPCAddr string `yaml:"grpc_addr"` // grpc.health.v1 endpoint, empty disables it
+}
+
+type TraceConf struct {
+	Exporter string  `yaml:"exporter"` // stdout | jaeger | otlp, empty disables tracing
+	Endpoint string  `yaml:"endpoint"` // jaeger collector, or OTLP/HTTP collector base URL
+	Ratio    float64 `yaml:"ratio"`    // share of new traces sampled, zero samples all
+}
+
 type LogConf struct {
-	Info  string `yaml:"info"`
-	Exc   string `yaml:"exc"`
-	Debug string `yaml:"debug"`
+	Info       string                     `yaml:"info"`
+	Exc        string                     `yaml:"exc"` // warn and above
+	Debug      string                     `yaml:"debug"`
+	Level      string                     `yaml:"level"`       // debug | info | warn | error
+	MaxSize    int                        `yaml:"max_size"`    // megabytes
+	MaxBackups int                        `yaml:"max_backups"` // rotated files kept per path
+	MaxAge     int                        `yaml:"max_age"`     // days
+	Sampling   map[string]LogSamplingConf `yaml:"sampling"`    // by level, unset levels are not sampled
+}
+
+type LogSamplingConf struct {
+	Initial    int `yaml:"initial"`    // entries per second logged for one message
+	Thereafter int `yaml:"thereafter"` // then every Nth
+}
+
+// DynamicConf holds the settings that take effect without a restart. Zero
+// leaves a setting at its built-in default.
+type DynamicConf struct {
+	FollowCountTTL int `yaml:"follow_count_ttl"` // seconds
+	FollowListTTL  int `yaml:"follow_list_ttl"`  // seconds
+	RecentWriteTTL int `yaml:"recent_write_ttl"` // seconds
+	BatchSize      int `yaml:"batch_size"`       // members per ZADD or ZSCAN
+	BatchSleep     int `yaml:"batch_sleep"`      // milliseconds between backfill batches
+	PageSize       int `yaml:"page_size"`        // page size when the caller asks for none
+	MaxPageSize    int `yaml:"max_page_size"`    // largest page a caller may ask for
+	SuggestFanout  int `yaml:"suggest_fanout"`   // follows read per user on each hop of a suggestion walk
+	SuggestTTL     int `yaml:"suggest_ttl"`      // seconds
+	HistoryTTL     int `yaml:"history_ttl"`      // seconds
 }
 
 type Conf struct {
-	Mysql        MysqlConf        `yaml:"mysql"`
-	Slave        MysqlConf        `yaml:"slave"`
-	RedisCluster RedisClusterConf `yaml:"cluster"`
-	MC           MCConf           `yaml:"mc"`
-	Grpc         GrpcConf         `yaml:"grpc"`
-	Etcd         EtcdConf         `yaml:"etcd"`
-	Kafka        KafkaConf        `yaml:"kafka"`
-	LogPath      LogConf          `yaml:"log_path"`
+	Mysql           MysqlConf        `yaml:"mysql"`
+	Slave           MysqlConf        `yaml:"slave"`
+	Slaves          []MysqlConf      `yaml:"slaves"`
+	Replica         ReplicaConf      `yaml:"replica"`
+	LocalCache      LocalCacheConf   `yaml:"local_cache"`
+	CountCache      string           `yaml:"count_cache"` // redis | memcache
+	Backfill        PoolConf         `yaml:"backfill"`
+	Admin           AdminConf        `yaml:"admin"`
+	Gateway         GatewayConf      `yaml:"gateway"`
+	History         HistoryConf      `yaml:"history"`
+	Trace           TraceConf        `yaml:"trace"`
+	Health          HealthConf       `yaml:"health"`
+	ShutdownTimeout int              `yaml:"shutdown_timeout"` // seconds
+	RedisCluster    RedisClusterConf `yaml:"cluster"`
+	MC              MCConf           `yaml:"mc"`
+	Grpc            GrpcConf         `yaml:"grpc"`
+	GRPCServer      GRPCServerConf   `yaml:"grpc_server"`
+	Transports      []string         `yaml:"transports"` // micro | grpc, defaults to micro
+	Etcd            EtcdConf         `yaml:"etcd"`
+	Kafka           KafkaConf        `yaml:"kafka"`
+	LogPath         LogConf          `yaml:"log_path"`
+	Dynamic         DynamicConf      `yaml:"dynamic"`
+}
+
+// Serves reports whether transport is one the service runs.
+func (c *Conf) Serves(transport string) bool {
+	if len(c.Transports) == 0 {
+		return transport == TransportMicro
+	}
+	for _, t := range c.Transports {
+		if t == transport {
+			return true
+		}
+	}
+	return false
 }
 
 func LoadYaml(path string) (*Conf, error) {
@@ -84,15 +202,18 @@ func LoadYaml(path string) (*Conf, error) {
 	if err != nil {
 		return nil, err
 	}
-	err = yaml.Unmarshal(y, conf)
-	return conf, err
+	err = yaml.UnmarshalStrict(y, conf)
+	if err != nil {
+		return nil, err
+	}
+	return conf, conf.Validate()
 }
 
 func GetMC(addr []string) *memcache.Client {
 	return memcache.New(addr...)
 }
 
-func GetRedisCluster(addr []string) redis.Cmdable {
+func GetRedisCluster(addr []string) *redis.ClusterClient {
 	return redis.NewClusterClient(&redis.ClusterOptions{
 		Addrs: addr,
 	})
@@ -110,21 +231,24 @@ func GetMysql(addr string) (sqlbuilder.Database, error) {
 	return r, nil
 }
 
-func GetGorm(addr string) (*gorm.DB, error) {
-	db, err := gorm.Open("mysql", addr)
+func GetGorm(config MysqlConf) (*gorm.DB, error) {
+	db, err := gorm.Open("mysql", config.DSN())
 	if err != nil {
 		return nil, err
 	}
-	db.DB().SetConnMaxLifetime(time.Minute * 3)
-	db.DB().SetMaxIdleConns(10)
-	db.DB().SetMaxOpenConns(50)
-	return db, nil
-}
-
-func InitLog(path string) (*log.Logger, error) {
-	fp, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0666)
-	if err != nil {
-		return nil, err
+	maxOpen, maxIdle := config.MaxOpen, config.MaxIdle
+	maxLifetime := time.Duration(config.MaxLifetime) * time.Second
+	if maxOpen == 0 {
+		maxOpen = DefaultMysqlMaxOpen
+	}
+	if maxIdle == 0 {
+		maxIdle = DefaultMysqlMaxIdle
 	}
-	return log.New(fp, "", log.LstdFlags|log.Lshortfile), nil
+	if maxLifetime == 0 {
+		maxLifetime = DefaultMysqlMaxLifetime
+	}
+	db.DB().SetConnMaxLifetime(maxLifetime)
+	db.DB().SetMaxIdleConns(maxIdle)
+	db.DB().SetMaxOpenConns(maxOpen)
+	return db, nil
 }
@@ -0,0 +1,152 @@
+package conf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	EnvPrefix          = "SOCIAL"
+	WatchRetryInterval = 10 * time.Second
+)
+
+// Loader builds a Conf from its sources and rebuilds it whenever one of
+// them changes. A rebuilt Conf that fails validation is dropped and the
+// previous one stays current.
+type Loader struct {
+	sources []Source
+
+	mu      sync.RWMutex
+	reload  sync.Mutex
+	current *Conf
+	subs    []func(*Conf)
+}
+
+func NewLoader(sources ...Source) *Loader {
+	return &Loader{sources: sources}
+}
+
+// LoadSocial loads the service config from the YAML file at path, then the
+// etcd key that file names in etcd.config_key, then the environment and
+// then extra, each overriding the ones before.
+func LoadSocial(path string, extra ...Source) (*Loader, *Conf, error) {
+	file := NewFileSource(path)
+	env := EnvSource{Prefix: EnvPrefix}
+	l := NewLoader(append([]Source{file, env}, extra...)...)
+	c, err := l.Load()
+	if err != nil || c.Etcd.ConfigKey == "" {
+		return l, c, err
+	}
+	etcd, err := NewEtcdSource(c.Etcd.Addr, c.Etcd.ConfigKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	l = NewLoader(append([]Source{file, etcd, env}, extra...)...)
+	c, err = l.Load()
+	if err != nil {
+		_ = etcd.Close()
+		return nil, nil, err
+	}
+	return l, c, nil
+}
+
+func (l *Loader) build() (*Conf, error) {
+	c := new(Conf)
+	for _, s := range l.sources {
+		if err := s.Apply(c); err != nil {
+			return nil, fmt.Errorf("%v: %v", s.Name(), err)
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (l *Loader) Load() (*Conf, error) {
+	c, err := l.build()
+	if err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.current = c
+	l.mu.Unlock()
+	return c, nil
+}
+
+func (l *Loader) Current() *Conf {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Subscribe calls fn with every Conf that replaces the current one. fn runs
+// on the watching goroutine and must not block for long.
+func (l *Loader) Subscribe(fn func(*Conf)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subs = append(l.subs, fn)
+}
+
+// Watch watches every source until ctx is done, reporting reload and watch
+// failures to onErr. A source whose watch fails is watched again after
+// WatchRetryInterval.
+func (l *Loader) Watch(ctx context.Context, onErr func(error)) {
+	for _, s := range l.sources {
+		go l.watch(ctx, s, onErr)
+	}
+}
+
+func (l *Loader) watch(ctx context.Context, s Source, onErr func(error)) {
+	for {
+		err := s.Watch(ctx, func() {
+			l.rebuild(onErr)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			onErr(fmt.Errorf("watch %v: %v", s.Name(), err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(WatchRetryInterval):
+		}
+		// whatever changed while the watch was down is picked up here
+		l.rebuild(onErr)
+	}
+}
+
+// Close releases the sources that hold connections.
+func (l *Loader) Close() error {
+	var rerr error
+	for _, s := range l.sources {
+		if c, ok := s.(io.Closer); ok {
+			if err := c.Close(); err != nil && rerr == nil {
+				rerr = err
+			}
+		}
+	}
+	return rerr
+}
+
+func (l *Loader) rebuild(onErr func(error)) {
+	l.reload.Lock()
+	defer l.reload.Unlock()
+	c, err := l.build()
+	if err != nil {
+		onErr(fmt.Errorf("reload: %v", err))
+		return
+	}
+	l.mu.Lock()
+	l.current = c
+	subs := l.subs
+	l.mu.Unlock()
+	for _, fn := range subs {
+		fn(c)
+	}
+}
@@ -0,0 +1,207 @@
+package conf
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	ConfigPathEnv = EnvPrefix + "_CONFIG"
+	secretMask    = "******"
+)
+
+// walk calls fn for every field of v that is not itself a struct, passing
+// the yaml path of the field.
+func walk(v reflect.Value, path []string, fn func(path []string, field reflect.Value) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		tag := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+		p := append(path[:len(path):len(path)], tag)
+		field := v.Field(i)
+		var err error
+		if field.Kind() == reflect.Struct {
+			err = walk(field, p, fn)
+		} else {
+			err = fn(p, field)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setField parses val into field. Scalars take their usual literal, lists
+// of strings may be comma separated and anything else is read as YAML, so
+// slaves or log_path.sampling can be overridden too.
+func setField(field reflect.Value, val string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(val), "[") {
+			field.Set(reflect.ValueOf(strings.Split(val, ",")))
+			return nil
+		}
+		fallthrough
+	default:
+		ptr := reflect.New(field.Type())
+		if err := yaml.UnmarshalStrict([]byte(val), ptr.Interface()); err != nil {
+			return err
+		}
+		field.Set(ptr.Elem())
+	}
+	return nil
+}
+
+// EnvSource overrides fields from environment variables named after their
+// yaml path, so SOCIAL_DYNAMIC_BATCH_SIZE sets dynamic.batch_size.
+type EnvSource struct {
+	Prefix string
+}
+
+func (s EnvSource) Name() string {
+	return "env " + s.Prefix
+}
+
+func (s EnvSource) Apply(c *Conf) error {
+	return walk(reflect.ValueOf(c).Elem(), nil, func(path []string, field reflect.Value) error {
+		name := s.Prefix + "_" + strings.ToUpper(strings.Join(path, "_"))
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		if err := setField(field, val); err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+		return nil
+	})
+}
+
+// Watch only waits: the environment of a running process does not change.
+func (s EnvSource) Watch(ctx context.Context, changed func()) error {
+	<-ctx.Done()
+	return nil
+}
+
+// FlagSource overrides fields from command line flags named after their
+// yaml path, such as -mysql.host or -dynamic.batch_size. Only flags given
+// on the command line override anything.
+type FlagSource struct {
+	set map[string]string
+}
+
+// NewFlagSource defines a flag on fs for every field of Conf.
+func NewFlagSource(fs *flag.FlagSet) *FlagSource {
+	s := &FlagSource{set: make(map[string]string)}
+	_ = walk(reflect.ValueOf(&Conf{}).Elem(), nil, func(path []string, field reflect.Value) error {
+		name := strings.Join(path, ".")
+		fs.Func(name, fmt.Sprintf("override %v (%v)", name, field.Type()), func(val string) error {
+			s.set[name] = val
+			return nil
+		})
+		return nil
+	})
+	return s
+}
+
+func (s *FlagSource) Name() string {
+	return "flags"
+}
+
+func (s *FlagSource) Apply(c *Conf) error {
+	return walk(reflect.ValueOf(c).Elem(), nil, func(path []string, field reflect.Value) error {
+		name := strings.Join(path, ".")
+		val, ok := s.set[name]
+		if !ok {
+			return nil
+		}
+		if err := setField(field, val); err != nil {
+			return fmt.Errorf("-%v: %v", name, err)
+		}
+		return nil
+	})
+}
+
+func (s *FlagSource) Watch(ctx context.Context, changed func()) error {
+	<-ctx.Done()
+	return nil
+}
+
+// ConfigPath is the config file to load when no -config flag is given.
+func ConfigPath() string {
+	if path := os.Getenv(ConfigPathEnv); path != "" {
+		return path
+	}
+	return SocialConfPath
+}
+
+// Masked returns a copy of c with every field tagged secret:"true" blanked
+// out, for printing.
+func (c *Conf) Masked() *Conf {
+	masked := *c
+	masked.Slaves = append([]MysqlConf(nil), c.Slaves...)
+	masked.GRPCServer.Tokens = append([]string(nil), c.GRPCServer.Tokens...)
+	masked.Admin.Tokens = append([]string(nil), c.Admin.Tokens...)
+	masked.Gateway.Tokens = append([]string(nil), c.Gateway.Tokens...)
+	mask(reflect.ValueOf(&masked).Elem())
+	return &masked
+}
+
+func mask(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).Tag.Get("secret") == "true" {
+				maskSecret(v.Field(i))
+				continue
+			}
+			mask(v.Field(i))
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			mask(v.Index(i))
+		}
+	}
+}
+
+func maskSecret(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		if v.String() != "" {
+			v.SetString(secretMask)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			maskSecret(v.Index(i))
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package conf
+
+import (
+	"context"
+	"github.com/coreos/etcd/clientv3"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+const (
+	DefaultFilePollInterval = 5 * time.Second
+	EtcdDialTimeout         = 5 * time.Second
+	EtcdGetTimeout          = 3 * time.Second
+)
+
+// Source is one layer of configuration. Layers are applied in order, so a
+// later one overrides whatever fields an earlier one set.
+type Source interface {
+	Name() string
+	Apply(c *Conf) error
+	// Watch calls changed whenever the layer may have changed and returns
+	// once ctx is done or watching fails.
+	Watch(ctx context.Context, changed func()) error
+}
+
+// FileSource is a YAML file, polled for changes.
+type FileSource struct {
+	Path     string
+	Interval time.Duration
+}
+
+func NewFileSource(path string) *FileSource {
+	return &FileSource{Path: path, Interval: DefaultFilePollInterval}
+}
+
+func (s *FileSource) Name() string {
+	return "file " + s.Path
+}
+
+func (s *FileSource) Apply(c *Conf) error {
+	y, err := ioutil.ReadFile(s.Path)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(y, c)
+}
+
+func (s *FileSource) Watch(ctx context.Context, changed func()) error {
+	var last time.Time
+	if fi, err := os.Stat(s.Path); err == nil {
+		last = fi.ModTime()
+	}
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		fi, err := os.Stat(s.Path)
+		if err != nil {
+			return err
+		}
+		if !fi.ModTime().Equal(last) {
+			last = fi.ModTime()
+			changed()
+		}
+	}
+}
+
+// EtcdSource is a YAML document stored under one etcd key. A missing key
+// contributes nothing.
+type EtcdSource struct {
+	Key    string
+	client *clientv3.Client
+}
+
+func NewEtcdSource(endpoints []string, key string) (*EtcdSource, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: EtcdDialTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &EtcdSource{Key: key, client: client}, nil
+}
+
+func (s *EtcdSource) Name() string {
+	return "etcd " + s.Key
+}
+
+func (s *EtcdSource) Apply(c *Conf) error {
+	ctx, cancel := context.WithTimeout(context.Background(), EtcdGetTimeout)
+	defer cancel()
+	rsp, err := s.client.Get(ctx, s.Key)
+	if err != nil {
+		return err
+	}
+	if len(rsp.Kvs) == 0 {
+		return nil
+	}
+	return yaml.UnmarshalStrict(rsp.Kvs[0].Value, c)
+}
+
+func (s *EtcdSource) Watch(ctx context.Context, changed func()) error {
+	for rsp := range s.client.Watch(ctx, s.Key) {
+		if err := rsp.Err(); err != nil {
+			return err
+		}
+		changed()
+	}
+	return nil
+}
+
+func (s *EtcdSource) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,110 @@
+package conf
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ValidationError lists every problem found in a Conf, one per line.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid config:\n  " + strings.Join(e, "\n  ")
+}
+
+func (e *ValidationError) add(format string, args ...interface{}) {
+	*e = append(*e, fmt.Sprintf(format, args...))
+}
+
+func (e *ValidationError) required(path string, set bool) {
+	if !set {
+		e.add("%v is required", path)
+	}
+}
+
+func (e *ValidationError) oneOf(path, val string, allowed ...string) {
+	for _, a := range allowed {
+		if val == a {
+			return
+		}
+	}
+	e.add("%v must be one of %v, got %q", path, strings.Join(allowed[1:], ", "), val)
+}
+
+func (e *ValidationError) mysql(path string, c MysqlConf) {
+	e.required(path+".host", c.Host != "")
+	if c.Port <= 0 || c.Port > 65535 {
+		e.add("%v.port must be between 1 and 65535, got %v", path, c.Port)
+	}
+	e.required(path+".db", c.DB != "")
+	e.required(path+".user", c.User != "")
+	if c.MaxOpen > 0 && c.MaxIdle > c.MaxOpen {
+		e.add("%v.max_idle %v exceeds max_open %v", path, c.MaxIdle, c.MaxOpen)
+	}
+}
+
+// Validate reports every missing or out of range field at once rather
+// than stopping at the first.
+func (c *Conf) Validate() error {
+	var errs ValidationError
+	errs.mysql("mysql", c.Mysql)
+	if len(c.Slaves) == 0 {
+		errs.mysql("slave", c.Slave)
+	}
+	for i, s := range c.Slaves {
+		errs.mysql(fmt.Sprintf("slaves[%v]", i), s)
+	}
+	errs.required("cluster.addr", len(c.RedisCluster.Addr) > 0)
+	errs.required("mc.addr", len(c.MC.Addr) > 0)
+	errs.required("grpc.name", c.Grpc.Name != "")
+	errs.required("grpc.addr", c.Grpc.Addr != "")
+	for _, t := range c.Transports {
+		errs.oneOf("transports", t, "", TransportMicro, TransportGRPC)
+	}
+	if c.Serves(TransportGRPC) {
+		errs.required("grpc_server.addr", c.GRPCServer.Addr != "")
+	}
+	errs.required("etcd.addr", len(c.Etcd.Addr) > 0)
+	errs.required("admin.addr", c.Admin.Addr != "")
+	errs.required("log_path.info", c.LogPath.Info != "")
+	errs.required("log_path.exc", c.LogPath.Exc != "")
+	errs.required("log_path.debug", c.LogPath.Debug != "")
+
+	// the empty string is allowed everywhere and picks the default
+	errs.oneOf("count_cache", c.CountCache, "", "redis", "memcache")
+	errs.oneOf("replica.policy", c.Replica.Policy, "", "round_robin", "least_conn")
+	errs.oneOf("backfill.overflow", c.Backfill.Overflow, "", "drop", "block", "caller_runs")
+	errs.oneOf("trace.exporter", c.Trace.Exporter, "", "stdout", "jaeger", "otlp")
+	if c.Trace.Exporter == "jaeger" || c.Trace.Exporter == "otlp" {
+		errs.required("trace.endpoint", c.Trace.Endpoint != "")
+	}
+	if c.Trace.Ratio < 0 || c.Trace.Ratio > 1 {
+		errs.add("trace.ratio must be between 0 and 1, got %v", c.Trace.Ratio)
+	}
+	levels := []string{"", "debug", "info", "warn", "error"}
+	errs.oneOf("log_path.level", c.LogPath.Level, levels...)
+	for lvl := range c.LogPath.Sampling {
+		errs.oneOf("log_path.sampling key", lvl, levels...)
+	}
+	if c.History.SnapshotAt != "" {
+		if _, err := time.Parse("15:04", c.History.SnapshotAt); err != nil {
+			errs.add("history.snapshot_at must be HH:MM, got %q", c.History.SnapshotAt)
+		}
+	}
+	if c.Dynamic.MaxPageSize > 0 && c.Dynamic.PageSize > c.Dynamic.MaxPageSize {
+		errs.add("dynamic.page_size %v exceeds dynamic.max_page_size %v", c.Dynamic.PageSize, c.Dynamic.MaxPageSize)
+	}
+	_ = walk(reflect.ValueOf(c).Elem(), nil, func(path []string, field reflect.Value) error {
+		if field.Kind() == reflect.Int && field.Int() < 0 {
+			errs.add("%v must not be negative, got %v", strings.Join(path, "."), field.Int())
+		}
+		return nil
+	})
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -1,9 +0,0 @@
-package global
-
-import "log"
-
-var (
-	InfoLog  *log.Logger
-	ExcLog   *log.Logger
-	DebugLog *log.Logger
-)
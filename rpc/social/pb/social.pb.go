@@ -7,6 +7,11 @@ import proto "github.com/golang/protobuf/proto"
 import fmt "fmt"
 import math "math"
 
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+)
+
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
 var _ = fmt.Errorf
@@ -22,6 +27,10 @@ type FollowItem struct {
 	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
 	TargetId             int64    `protobuf:"varint,2,opt,name=target_id,json=targetId" json:"target_id,omitempty"`
 	FollowType           int32    `protobuf:"varint,3,opt,name=follow_type,json=followType" json:"follow_type,omitempty"`
+	Notify               int32    `protobuf:"varint,4,opt,name=notify" json:"notify,omitempty"`
+	Special              bool     `protobuf:"varint,5,opt,name=special" json:"special,omitempty"`
+	Source               string   `protobuf:"bytes,6,opt,name=source" json:"source,omitempty"`
+	Client               string   `protobuf:"bytes,7,opt,name=client" json:"client,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -72,6 +81,34 @@ func (m *FollowItem) GetFollowType() int32 {
 	return 0
 }
 
+func (m *FollowItem) GetNotify() int32 {
+	if m != nil {
+		return m.Notify
+	}
+	return 0
+}
+
+func (m *FollowItem) GetSpecial() bool {
+	if m != nil {
+		return m.Special
+	}
+	return false
+}
+
+func (m *FollowItem) GetSource() string {
+	if m != nil {
+		return m.Source
+	}
+	return ""
+}
+
+func (m *FollowItem) GetClient() string {
+	if m != nil {
+		return m.Client
+	}
+	return ""
+}
+
 type FollowRequest struct {
 	FollowItem           *FollowItem `protobuf:"bytes,1,opt,name=follow_item,json=followItem" json:"follow_item,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
@@ -454,51 +491,2168 @@ func (m *FollowAllResponse) GetUids() []int64 {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*FollowItem)(nil), "social.FollowItem")
-	proto.RegisterType((*FollowRequest)(nil), "social.FollowRequest")
-	proto.RegisterType((*EmptyResponse)(nil), "social.EmptyResponse")
-	proto.RegisterType((*UnfollowRequest)(nil), "social.UnfollowRequest")
-	proto.RegisterType((*ListRequest)(nil), "social.ListRequest")
-	proto.RegisterType((*ListResponse)(nil), "social.ListResponse")
-	proto.RegisterType((*CountRequest)(nil), "social.CountRequest")
-	proto.RegisterType((*CountResponse)(nil), "social.CountResponse")
-	proto.RegisterType((*FollowAllRequest)(nil), "social.FollowAllRequest")
-	proto.RegisterType((*FollowAllResponse)(nil), "social.FollowAllResponse")
+type SuggestionRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	Limit                int64    `protobuf:"varint,2,opt,name=limit" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SuggestionRequest) Reset()         { *m = SuggestionRequest{} }
+func (m *SuggestionRequest) String() string { return proto.CompactTextString(m) }
+func (*SuggestionRequest) ProtoMessage()    {}
+func (*SuggestionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{10}
+}
+func (m *SuggestionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SuggestionRequest.Unmarshal(m, b)
+}
+func (m *SuggestionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SuggestionRequest.Marshal(b, m, deterministic)
+}
+func (dst *SuggestionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuggestionRequest.Merge(dst, src)
+}
+func (m *SuggestionRequest) XXX_Size() int {
+	return xxx_messageInfo_SuggestionRequest.Size(m)
+}
+func (m *SuggestionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuggestionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuggestionRequest proto.InternalMessageInfo
+
+func (m *SuggestionRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *SuggestionRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type Suggestion struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	Mutual               int64    `protobuf:"varint,2,opt,name=mutual" json:"mutual,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Suggestion) Reset()         { *m = Suggestion{} }
+func (m *Suggestion) String() string { return proto.CompactTextString(m) }
+func (*Suggestion) ProtoMessage()    {}
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{11}
+}
+func (m *Suggestion) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Suggestion.Unmarshal(m, b)
+}
+func (m *Suggestion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Suggestion.Marshal(b, m, deterministic)
+}
+func (dst *Suggestion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Suggestion.Merge(dst, src)
+}
+func (m *Suggestion) XXX_Size() int {
+	return xxx_messageInfo_Suggestion.Size(m)
+}
+func (m *Suggestion) XXX_DiscardUnknown() {
+	xxx_messageInfo_Suggestion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Suggestion proto.InternalMessageInfo
+
+func (m *Suggestion) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *Suggestion) GetMutual() int64 {
+	if m != nil {
+		return m.Mutual
+	}
+	return 0
+}
+
+type SuggestionResponse struct {
+	Suggestions          []*Suggestion `protobuf:"bytes,1,rep,name=suggestions" json:"suggestions,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SuggestionResponse) Reset()         { *m = SuggestionResponse{} }
+func (m *SuggestionResponse) String() string { return proto.CompactTextString(m) }
+func (*SuggestionResponse) ProtoMessage()    {}
+func (*SuggestionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{12}
+}
+func (m *SuggestionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SuggestionResponse.Unmarshal(m, b)
+}
+func (m *SuggestionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SuggestionResponse.Marshal(b, m, deterministic)
+}
+func (dst *SuggestionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuggestionResponse.Merge(dst, src)
+}
+func (m *SuggestionResponse) XXX_Size() int {
+	return xxx_messageInfo_SuggestionResponse.Size(m)
+}
+func (m *SuggestionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuggestionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuggestionResponse proto.InternalMessageInfo
+
+func (m *SuggestionResponse) GetSuggestions() []*Suggestion {
+	if m != nil {
+		return m.Suggestions
+	}
+	return nil
+}
+
+type CommonRequest struct {
+	ViewerUid            int64    `protobuf:"varint,1,opt,name=viewer_uid,json=viewerUid" json:"viewer_uid,omitempty"`
+	TargetUid            int64    `protobuf:"varint,2,opt,name=target_uid,json=targetUid" json:"target_uid,omitempty"`
+	Limit                int64    `protobuf:"varint,3,opt,name=limit" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommonRequest) Reset()         { *m = CommonRequest{} }
+func (m *CommonRequest) String() string { return proto.CompactTextString(m) }
+func (*CommonRequest) ProtoMessage()    {}
+func (*CommonRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{13}
+}
+func (m *CommonRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommonRequest.Unmarshal(m, b)
+}
+func (m *CommonRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommonRequest.Marshal(b, m, deterministic)
+}
+func (dst *CommonRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommonRequest.Merge(dst, src)
+}
+func (m *CommonRequest) XXX_Size() int {
+	return xxx_messageInfo_CommonRequest.Size(m)
+}
+func (m *CommonRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommonRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommonRequest proto.InternalMessageInfo
+
+func (m *CommonRequest) GetViewerUid() int64 {
+	if m != nil {
+		return m.ViewerUid
+	}
+	return 0
+}
+
+func (m *CommonRequest) GetTargetUid() int64 {
+	if m != nil {
+		return m.TargetUid
+	}
+	return 0
+}
+
+func (m *CommonRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type CommonResponse struct {
+	Uids                 []int64  `protobuf:"varint,1,rep,packed,name=uids" json:"uids,omitempty"`
+	Total                int64    `protobuf:"varint,2,opt,name=total" json:"total,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CommonResponse) Reset()         { *m = CommonResponse{} }
+func (m *CommonResponse) String() string { return proto.CompactTextString(m) }
+func (*CommonResponse) ProtoMessage()    {}
+func (*CommonResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{14}
+}
+func (m *CommonResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CommonResponse.Unmarshal(m, b)
+}
+func (m *CommonResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CommonResponse.Marshal(b, m, deterministic)
+}
+func (dst *CommonResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommonResponse.Merge(dst, src)
+}
+func (m *CommonResponse) XXX_Size() int {
+	return xxx_messageInfo_CommonResponse.Size(m)
+}
+func (m *CommonResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommonResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommonResponse proto.InternalMessageInfo
+
+func (m *CommonResponse) GetUids() []int64 {
+	if m != nil {
+		return m.Uids
+	}
+	return nil
+}
+
+func (m *CommonResponse) GetTotal() int64 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+type FollowGroup struct {
+	GroupId              int64    `protobuf:"varint,1,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Uid                  int64    `protobuf:"varint,2,opt,name=uid" json:"uid,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Ctime                int64    `protobuf:"varint,4,opt,name=ctime" json:"ctime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FollowGroup) Reset()         { *m = FollowGroup{} }
+func (m *FollowGroup) String() string { return proto.CompactTextString(m) }
+func (*FollowGroup) ProtoMessage()    {}
+func (*FollowGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{15}
+}
+func (m *FollowGroup) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowGroup.Unmarshal(m, b)
+}
+func (m *FollowGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowGroup.Marshal(b, m, deterministic)
+}
+func (dst *FollowGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowGroup.Merge(dst, src)
+}
+func (m *FollowGroup) XXX_Size() int {
+	return xxx_messageInfo_FollowGroup.Size(m)
+}
+func (m *FollowGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowGroup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowGroup proto.InternalMessageInfo
+
+func (m *FollowGroup) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *FollowGroup) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *FollowGroup) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *FollowGroup) GetCtime() int64 {
+	if m != nil {
+		return m.Ctime
+	}
+	return 0
+}
+
+type GroupRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	GroupId              int64    `protobuf:"varint,2,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	Name                 string   `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupRequest) Reset()         { *m = GroupRequest{} }
+func (m *GroupRequest) String() string { return proto.CompactTextString(m) }
+func (*GroupRequest) ProtoMessage()    {}
+func (*GroupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{16}
+}
+func (m *GroupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupRequest.Unmarshal(m, b)
+}
+func (m *GroupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupRequest.Marshal(b, m, deterministic)
+}
+func (dst *GroupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupRequest.Merge(dst, src)
+}
+func (m *GroupRequest) XXX_Size() int {
+	return xxx_messageInfo_GroupRequest.Size(m)
+}
+func (m *GroupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupRequest proto.InternalMessageInfo
+
+func (m *GroupRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *GroupRequest) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *GroupRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type GroupResponse struct {
+	Group                *FollowGroup `protobuf:"bytes,1,opt,name=group" json:"group,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *GroupResponse) Reset()         { *m = GroupResponse{} }
+func (m *GroupResponse) String() string { return proto.CompactTextString(m) }
+func (*GroupResponse) ProtoMessage()    {}
+func (*GroupResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{17}
+}
+func (m *GroupResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupResponse.Unmarshal(m, b)
+}
+func (m *GroupResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupResponse.Marshal(b, m, deterministic)
+}
+func (dst *GroupResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupResponse.Merge(dst, src)
+}
+func (m *GroupResponse) XXX_Size() int {
+	return xxx_messageInfo_GroupResponse.Size(m)
+}
+func (m *GroupResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupResponse proto.InternalMessageInfo
+
+func (m *GroupResponse) GetGroup() *FollowGroup {
+	if m != nil {
+		return m.Group
+	}
+	return nil
+}
+
+type GroupListRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupListRequest) Reset()         { *m = GroupListRequest{} }
+func (m *GroupListRequest) String() string { return proto.CompactTextString(m) }
+func (*GroupListRequest) ProtoMessage()    {}
+func (*GroupListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{18}
+}
+func (m *GroupListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupListRequest.Unmarshal(m, b)
+}
+func (m *GroupListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupListRequest.Marshal(b, m, deterministic)
+}
+func (dst *GroupListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupListRequest.Merge(dst, src)
+}
+func (m *GroupListRequest) XXX_Size() int {
+	return xxx_messageInfo_GroupListRequest.Size(m)
+}
+func (m *GroupListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupListRequest proto.InternalMessageInfo
+
+func (m *GroupListRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+type GroupListResponse struct {
+	Groups               []*FollowGroup `protobuf:"bytes,1,rep,name=groups" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *GroupListResponse) Reset()         { *m = GroupListResponse{} }
+func (m *GroupListResponse) String() string { return proto.CompactTextString(m) }
+func (*GroupListResponse) ProtoMessage()    {}
+func (*GroupListResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{19}
+}
+func (m *GroupListResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupListResponse.Unmarshal(m, b)
+}
+func (m *GroupListResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupListResponse.Marshal(b, m, deterministic)
+}
+func (dst *GroupListResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupListResponse.Merge(dst, src)
+}
+func (m *GroupListResponse) XXX_Size() int {
+	return xxx_messageInfo_GroupListResponse.Size(m)
+}
+func (m *GroupListResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupListResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupListResponse proto.InternalMessageInfo
+
+func (m *GroupListResponse) GetGroups() []*FollowGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type GroupMembersRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	GroupId              int64    `protobuf:"varint,2,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	MemberUids           []int64  `protobuf:"varint,3,rep,packed,name=member_uids,json=memberUids" json:"member_uids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupMembersRequest) Reset()         { *m = GroupMembersRequest{} }
+func (m *GroupMembersRequest) String() string { return proto.CompactTextString(m) }
+func (*GroupMembersRequest) ProtoMessage()    {}
+func (*GroupMembersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{20}
+}
+func (m *GroupMembersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupMembersRequest.Unmarshal(m, b)
+}
+func (m *GroupMembersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupMembersRequest.Marshal(b, m, deterministic)
+}
+func (dst *GroupMembersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupMembersRequest.Merge(dst, src)
+}
+func (m *GroupMembersRequest) XXX_Size() int {
+	return xxx_messageInfo_GroupMembersRequest.Size(m)
+}
+func (m *GroupMembersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupMembersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupMembersRequest proto.InternalMessageInfo
+
+func (m *GroupMembersRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *GroupMembersRequest) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *GroupMembersRequest) GetMemberUids() []int64 {
+	if m != nil {
+		return m.MemberUids
+	}
+	return nil
+}
+
+type GroupMemberListRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	GroupId              int64    `protobuf:"varint,2,opt,name=group_id,json=groupId" json:"group_id,omitempty"`
+	LastId               int64    `protobuf:"varint,3,opt,name=last_id,json=lastId" json:"last_id,omitempty"`
+	Offset               int64    `protobuf:"varint,4,opt,name=offset" json:"offset,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GroupMemberListRequest) Reset()         { *m = GroupMemberListRequest{} }
+func (m *GroupMemberListRequest) String() string { return proto.CompactTextString(m) }
+func (*GroupMemberListRequest) ProtoMessage()    {}
+func (*GroupMemberListRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{21}
+}
+func (m *GroupMemberListRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GroupMemberListRequest.Unmarshal(m, b)
+}
+func (m *GroupMemberListRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GroupMemberListRequest.Marshal(b, m, deterministic)
+}
+func (dst *GroupMemberListRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GroupMemberListRequest.Merge(dst, src)
+}
+func (m *GroupMemberListRequest) XXX_Size() int {
+	return xxx_messageInfo_GroupMemberListRequest.Size(m)
+}
+func (m *GroupMemberListRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GroupMemberListRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GroupMemberListRequest proto.InternalMessageInfo
+
+func (m *GroupMemberListRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *GroupMemberListRequest) GetGroupId() int64 {
+	if m != nil {
+		return m.GroupId
+	}
+	return 0
+}
+
+func (m *GroupMemberListRequest) GetLastId() int64 {
+	if m != nil {
+		return m.LastId
+	}
+	return 0
+}
+
+func (m *GroupMemberListRequest) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+type NotifyFollowersRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	Notify               int32    `protobuf:"varint,2,opt,name=notify" json:"notify,omitempty"`
+	SpecialOnly          bool     `protobuf:"varint,3,opt,name=special_only,json=specialOnly" json:"special_only,omitempty"`
+	LastId               int64    `protobuf:"varint,4,opt,name=last_id,json=lastId" json:"last_id,omitempty"`
+	Limit                int64    `protobuf:"varint,5,opt,name=limit" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *NotifyFollowersRequest) Reset()         { *m = NotifyFollowersRequest{} }
+func (m *NotifyFollowersRequest) String() string { return proto.CompactTextString(m) }
+func (*NotifyFollowersRequest) ProtoMessage()    {}
+func (*NotifyFollowersRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{22}
+}
+func (m *NotifyFollowersRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NotifyFollowersRequest.Unmarshal(m, b)
+}
+func (m *NotifyFollowersRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NotifyFollowersRequest.Marshal(b, m, deterministic)
+}
+func (dst *NotifyFollowersRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NotifyFollowersRequest.Merge(dst, src)
+}
+func (m *NotifyFollowersRequest) XXX_Size() int {
+	return xxx_messageInfo_NotifyFollowersRequest.Size(m)
+}
+func (m *NotifyFollowersRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_NotifyFollowersRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NotifyFollowersRequest proto.InternalMessageInfo
+
+func (m *NotifyFollowersRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *NotifyFollowersRequest) GetNotify() int32 {
+	if m != nil {
+		return m.Notify
+	}
+	return 0
+}
+
+func (m *NotifyFollowersRequest) GetSpecialOnly() bool {
+	if m != nil {
+		return m.SpecialOnly
+	}
+	return false
+}
+
+func (m *NotifyFollowersRequest) GetLastId() int64 {
+	if m != nil {
+		return m.LastId
+	}
+	return 0
+}
+
+func (m *NotifyFollowersRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type NotifyFollowersResponse struct {
+	Followers            []*FollowItem `protobuf:"bytes,1,rep,name=followers" json:"followers,omitempty"`
+	HasMore              bool          `protobuf:"varint,2,opt,name=has_more,json=hasMore" json:"has_more,omitempty"`
+	NextId               int64         `protobuf:"varint,3,opt,name=next_id,json=nextId" json:"next_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *NotifyFollowersResponse) Reset()         { *m = NotifyFollowersResponse{} }
+func (m *NotifyFollowersResponse) String() string { return proto.CompactTextString(m) }
+func (*NotifyFollowersResponse) ProtoMessage()    {}
+func (*NotifyFollowersResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{23}
+}
+func (m *NotifyFollowersResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_NotifyFollowersResponse.Unmarshal(m, b)
+}
+func (m *NotifyFollowersResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_NotifyFollowersResponse.Marshal(b, m, deterministic)
+}
+func (dst *NotifyFollowersResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_NotifyFollowersResponse.Merge(dst, src)
+}
+func (m *NotifyFollowersResponse) XXX_Size() int {
+	return xxx_messageInfo_NotifyFollowersResponse.Size(m)
+}
+func (m *NotifyFollowersResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_NotifyFollowersResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_NotifyFollowersResponse proto.InternalMessageInfo
+
+func (m *NotifyFollowersResponse) GetFollowers() []*FollowItem {
+	if m != nil {
+		return m.Followers
+	}
+	return nil
+}
+
+func (m *NotifyFollowersResponse) GetHasMore() bool {
+	if m != nil {
+		return m.HasMore
+	}
+	return false
+}
+
+func (m *NotifyFollowersResponse) GetNextId() int64 {
+	if m != nil {
+		return m.NextId
+	}
+	return 0
+}
+
+type FollowStatsRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	From                 string   `protobuf:"bytes,2,opt,name=from" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,3,opt,name=to" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FollowStatsRequest) Reset()         { *m = FollowStatsRequest{} }
+func (m *FollowStatsRequest) String() string { return proto.CompactTextString(m) }
+func (*FollowStatsRequest) ProtoMessage()    {}
+func (*FollowStatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{24}
+}
+func (m *FollowStatsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowStatsRequest.Unmarshal(m, b)
+}
+func (m *FollowStatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowStatsRequest.Marshal(b, m, deterministic)
+}
+func (dst *FollowStatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowStatsRequest.Merge(dst, src)
+}
+func (m *FollowStatsRequest) XXX_Size() int {
+	return xxx_messageInfo_FollowStatsRequest.Size(m)
+}
+func (m *FollowStatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowStatsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowStatsRequest proto.InternalMessageInfo
+
+func (m *FollowStatsRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *FollowStatsRequest) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *FollowStatsRequest) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type FollowStat struct {
+	Day                  string   `protobuf:"bytes,1,opt,name=day" json:"day,omitempty"`
+	Gained               int64    `protobuf:"varint,2,opt,name=gained" json:"gained,omitempty"`
+	Lost                 int64    `protobuf:"varint,3,opt,name=lost" json:"lost,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FollowStat) Reset()         { *m = FollowStat{} }
+func (m *FollowStat) String() string { return proto.CompactTextString(m) }
+func (*FollowStat) ProtoMessage()    {}
+func (*FollowStat) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{25}
+}
+func (m *FollowStat) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowStat.Unmarshal(m, b)
+}
+func (m *FollowStat) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowStat.Marshal(b, m, deterministic)
+}
+func (dst *FollowStat) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowStat.Merge(dst, src)
+}
+func (m *FollowStat) XXX_Size() int {
+	return xxx_messageInfo_FollowStat.Size(m)
+}
+func (m *FollowStat) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowStat.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowStat proto.InternalMessageInfo
+
+func (m *FollowStat) GetDay() string {
+	if m != nil {
+		return m.Day
+	}
+	return ""
+}
+
+func (m *FollowStat) GetGained() int64 {
+	if m != nil {
+		return m.Gained
+	}
+	return 0
+}
+
+func (m *FollowStat) GetLost() int64 {
+	if m != nil {
+		return m.Lost
+	}
+	return 0
+}
+
+type FollowStatsResponse struct {
+	Stats                []*FollowStat `protobuf:"bytes,1,rep,name=stats" json:"stats,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *FollowStatsResponse) Reset()         { *m = FollowStatsResponse{} }
+func (m *FollowStatsResponse) String() string { return proto.CompactTextString(m) }
+func (*FollowStatsResponse) ProtoMessage()    {}
+func (*FollowStatsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{26}
+}
+func (m *FollowStatsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowStatsResponse.Unmarshal(m, b)
+}
+func (m *FollowStatsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowStatsResponse.Marshal(b, m, deterministic)
+}
+func (dst *FollowStatsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowStatsResponse.Merge(dst, src)
+}
+func (m *FollowStatsResponse) XXX_Size() int {
+	return xxx_messageInfo_FollowStatsResponse.Size(m)
+}
+func (m *FollowStatsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowStatsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowStatsResponse proto.InternalMessageInfo
+
+func (m *FollowStatsResponse) GetStats() []*FollowStat {
+	if m != nil {
+		return m.Stats
+	}
+	return nil
+}
+
+type FollowerHistoryRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	Granularity          string   `protobuf:"bytes,2,opt,name=granularity" json:"granularity,omitempty"`
+	From                 string   `protobuf:"bytes,3,opt,name=from" json:"from,omitempty"`
+	To                   string   `protobuf:"bytes,4,opt,name=to" json:"to,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FollowerHistoryRequest) Reset()         { *m = FollowerHistoryRequest{} }
+func (m *FollowerHistoryRequest) String() string { return proto.CompactTextString(m) }
+func (*FollowerHistoryRequest) ProtoMessage()    {}
+func (*FollowerHistoryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{27}
+}
+func (m *FollowerHistoryRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowerHistoryRequest.Unmarshal(m, b)
+}
+func (m *FollowerHistoryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowerHistoryRequest.Marshal(b, m, deterministic)
+}
+func (dst *FollowerHistoryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowerHistoryRequest.Merge(dst, src)
+}
+func (m *FollowerHistoryRequest) XXX_Size() int {
+	return xxx_messageInfo_FollowerHistoryRequest.Size(m)
+}
+func (m *FollowerHistoryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowerHistoryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowerHistoryRequest proto.InternalMessageInfo
+
+func (m *FollowerHistoryRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *FollowerHistoryRequest) GetGranularity() string {
+	if m != nil {
+		return m.Granularity
+	}
+	return ""
+}
+
+func (m *FollowerHistoryRequest) GetFrom() string {
+	if m != nil {
+		return m.From
+	}
+	return ""
+}
+
+func (m *FollowerHistoryRequest) GetTo() string {
+	if m != nil {
+		return m.To
+	}
+	return ""
+}
+
+type FollowerHistoryPoint struct {
+	Period               string   `protobuf:"bytes,1,opt,name=period" json:"period,omitempty"`
+	FollowerCount        int64    `protobuf:"varint,2,opt,name=follower_count,json=followerCount" json:"follower_count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FollowerHistoryPoint) Reset()         { *m = FollowerHistoryPoint{} }
+func (m *FollowerHistoryPoint) String() string { return proto.CompactTextString(m) }
+func (*FollowerHistoryPoint) ProtoMessage()    {}
+func (*FollowerHistoryPoint) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{28}
+}
+func (m *FollowerHistoryPoint) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowerHistoryPoint.Unmarshal(m, b)
+}
+func (m *FollowerHistoryPoint) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowerHistoryPoint.Marshal(b, m, deterministic)
+}
+func (dst *FollowerHistoryPoint) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowerHistoryPoint.Merge(dst, src)
+}
+func (m *FollowerHistoryPoint) XXX_Size() int {
+	return xxx_messageInfo_FollowerHistoryPoint.Size(m)
+}
+func (m *FollowerHistoryPoint) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowerHistoryPoint.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowerHistoryPoint proto.InternalMessageInfo
+
+func (m *FollowerHistoryPoint) GetPeriod() string {
+	if m != nil {
+		return m.Period
+	}
+	return ""
+}
+
+func (m *FollowerHistoryPoint) GetFollowerCount() int64 {
+	if m != nil {
+		return m.FollowerCount
+	}
+	return 0
+}
+
+type FollowerHistoryResponse struct {
+	Points               []*FollowerHistoryPoint `protobuf:"bytes,1,rep,name=points" json:"points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *FollowerHistoryResponse) Reset()         { *m = FollowerHistoryResponse{} }
+func (m *FollowerHistoryResponse) String() string { return proto.CompactTextString(m) }
+func (*FollowerHistoryResponse) ProtoMessage()    {}
+func (*FollowerHistoryResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{29}
+}
+func (m *FollowerHistoryResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FollowerHistoryResponse.Unmarshal(m, b)
+}
+func (m *FollowerHistoryResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FollowerHistoryResponse.Marshal(b, m, deterministic)
+}
+func (dst *FollowerHistoryResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FollowerHistoryResponse.Merge(dst, src)
+}
+func (m *FollowerHistoryResponse) XXX_Size() int {
+	return xxx_messageInfo_FollowerHistoryResponse.Size(m)
+}
+func (m *FollowerHistoryResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_FollowerHistoryResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FollowerHistoryResponse proto.InternalMessageInfo
+
+func (m *FollowerHistoryResponse) GetPoints() []*FollowerHistoryPoint {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+type RestoreRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	FollowType           int32    `protobuf:"varint,2,opt,name=follow_type,json=followType" json:"follow_type,omitempty"`
+	Since                int64    `protobuf:"varint,3,opt,name=since" json:"since,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreRequest) Reset()         { *m = RestoreRequest{} }
+func (m *RestoreRequest) String() string { return proto.CompactTextString(m) }
+func (*RestoreRequest) ProtoMessage()    {}
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{30}
+}
+func (m *RestoreRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreRequest.Unmarshal(m, b)
+}
+func (m *RestoreRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreRequest.Marshal(b, m, deterministic)
+}
+func (dst *RestoreRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreRequest.Merge(dst, src)
+}
+func (m *RestoreRequest) XXX_Size() int {
+	return xxx_messageInfo_RestoreRequest.Size(m)
+}
+func (m *RestoreRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreRequest proto.InternalMessageInfo
+
+func (m *RestoreRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *RestoreRequest) GetFollowType() int32 {
+	if m != nil {
+		return m.FollowType
+	}
+	return 0
+}
+
+func (m *RestoreRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+type RestoreResponse struct {
+	TargetIds            []int64  `protobuf:"varint,1,rep,packed,name=target_ids,json=targetIds" json:"target_ids,omitempty"`
+	HasMore              bool     `protobuf:"varint,2,opt,name=has_more,json=hasMore" json:"has_more,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RestoreResponse) Reset()         { *m = RestoreResponse{} }
+func (m *RestoreResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreResponse) ProtoMessage()    {}
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{31}
+}
+func (m *RestoreResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RestoreResponse.Unmarshal(m, b)
+}
+func (m *RestoreResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RestoreResponse.Marshal(b, m, deterministic)
+}
+func (dst *RestoreResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RestoreResponse.Merge(dst, src)
+}
+func (m *RestoreResponse) XXX_Size() int {
+	return xxx_messageInfo_RestoreResponse.Size(m)
+}
+func (m *RestoreResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RestoreResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RestoreResponse proto.InternalMessageInfo
+
+func (m *RestoreResponse) GetTargetIds() []int64 {
+	if m != nil {
+		return m.TargetIds
+	}
+	return nil
+}
+
+func (m *RestoreResponse) GetHasMore() bool {
+	if m != nil {
+		return m.HasMore
+	}
+	return false
+}
+
+type GraphDeletionRequest struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GraphDeletionRequest) Reset()         { *m = GraphDeletionRequest{} }
+func (m *GraphDeletionRequest) String() string { return proto.CompactTextString(m) }
+func (*GraphDeletionRequest) ProtoMessage()    {}
+func (*GraphDeletionRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{32}
+}
+func (m *GraphDeletionRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GraphDeletionRequest.Unmarshal(m, b)
+}
+func (m *GraphDeletionRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GraphDeletionRequest.Marshal(b, m, deterministic)
+}
+func (dst *GraphDeletionRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GraphDeletionRequest.Merge(dst, src)
+}
+func (m *GraphDeletionRequest) XXX_Size() int {
+	return xxx_messageInfo_GraphDeletionRequest.Size(m)
+}
+func (m *GraphDeletionRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GraphDeletionRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GraphDeletionRequest proto.InternalMessageInfo
+
+func (m *GraphDeletionRequest) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+type GraphDeletionResponse struct {
+	Uid                  int64    `protobuf:"varint,1,opt,name=uid" json:"uid,omitempty"`
+	Done                 bool     `protobuf:"varint,2,opt,name=done" json:"done,omitempty"`
+	Follows              int64    `protobuf:"varint,3,opt,name=follows" json:"follows,omitempty"`
+	Followers            int64    `protobuf:"varint,4,opt,name=followers" json:"followers,omitempty"`
+	Topics               int64    `protobuf:"varint,5,opt,name=topics" json:"topics,omitempty"`
+	Ctime                int64    `protobuf:"varint,6,opt,name=ctime" json:"ctime,omitempty"`
+	Mtime                int64    `protobuf:"varint,7,opt,name=mtime" json:"mtime,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GraphDeletionResponse) Reset()         { *m = GraphDeletionResponse{} }
+func (m *GraphDeletionResponse) String() string { return proto.CompactTextString(m) }
+func (*GraphDeletionResponse) ProtoMessage()    {}
+func (*GraphDeletionResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_social_6f0a2fe2d7e7fb81, []int{33}
+}
+func (m *GraphDeletionResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GraphDeletionResponse.Unmarshal(m, b)
+}
+func (m *GraphDeletionResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GraphDeletionResponse.Marshal(b, m, deterministic)
+}
+func (dst *GraphDeletionResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GraphDeletionResponse.Merge(dst, src)
+}
+func (m *GraphDeletionResponse) XXX_Size() int {
+	return xxx_messageInfo_GraphDeletionResponse.Size(m)
+}
+func (m *GraphDeletionResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_GraphDeletionResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GraphDeletionResponse proto.InternalMessageInfo
+
+func (m *GraphDeletionResponse) GetUid() int64 {
+	if m != nil {
+		return m.Uid
+	}
+	return 0
+}
+
+func (m *GraphDeletionResponse) GetDone() bool {
+	if m != nil {
+		return m.Done
+	}
+	return false
+}
+
+func (m *GraphDeletionResponse) GetFollows() int64 {
+	if m != nil {
+		return m.Follows
+	}
+	return 0
+}
+
+func (m *GraphDeletionResponse) GetFollowers() int64 {
+	if m != nil {
+		return m.Followers
+	}
+	return 0
+}
+
+func (m *GraphDeletionResponse) GetTopics() int64 {
+	if m != nil {
+		return m.Topics
+	}
+	return 0
+}
+
+func (m *GraphDeletionResponse) GetCtime() int64 {
+	if m != nil {
+		return m.Ctime
+	}
+	return 0
+}
+
+func (m *GraphDeletionResponse) GetMtime() int64 {
+	if m != nil {
+		return m.Mtime
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*FollowItem)(nil), "social.FollowItem")
+	proto.RegisterType((*FollowRequest)(nil), "social.FollowRequest")
+	proto.RegisterType((*EmptyResponse)(nil), "social.EmptyResponse")
+	proto.RegisterType((*UnfollowRequest)(nil), "social.UnfollowRequest")
+	proto.RegisterType((*ListRequest)(nil), "social.ListRequest")
+	proto.RegisterType((*ListResponse)(nil), "social.ListResponse")
+	proto.RegisterType((*CountRequest)(nil), "social.CountRequest")
+	proto.RegisterType((*CountResponse)(nil), "social.CountResponse")
+	proto.RegisterType((*FollowAllRequest)(nil), "social.FollowAllRequest")
+	proto.RegisterType((*FollowAllResponse)(nil), "social.FollowAllResponse")
+	proto.RegisterType((*SuggestionRequest)(nil), "social.SuggestionRequest")
+	proto.RegisterType((*Suggestion)(nil), "social.Suggestion")
+	proto.RegisterType((*SuggestionResponse)(nil), "social.SuggestionResponse")
+	proto.RegisterType((*CommonRequest)(nil), "social.CommonRequest")
+	proto.RegisterType((*CommonResponse)(nil), "social.CommonResponse")
+	proto.RegisterType((*FollowGroup)(nil), "social.FollowGroup")
+	proto.RegisterType((*GroupRequest)(nil), "social.GroupRequest")
+	proto.RegisterType((*GroupResponse)(nil), "social.GroupResponse")
+	proto.RegisterType((*GroupListRequest)(nil), "social.GroupListRequest")
+	proto.RegisterType((*GroupListResponse)(nil), "social.GroupListResponse")
+	proto.RegisterType((*GroupMembersRequest)(nil), "social.GroupMembersRequest")
+	proto.RegisterType((*GroupMemberListRequest)(nil), "social.GroupMemberListRequest")
+	proto.RegisterType((*NotifyFollowersRequest)(nil), "social.NotifyFollowersRequest")
+	proto.RegisterType((*NotifyFollowersResponse)(nil), "social.NotifyFollowersResponse")
+	proto.RegisterType((*FollowStatsRequest)(nil), "social.FollowStatsRequest")
+	proto.RegisterType((*FollowStat)(nil), "social.FollowStat")
+	proto.RegisterType((*FollowStatsResponse)(nil), "social.FollowStatsResponse")
+	proto.RegisterType((*FollowerHistoryRequest)(nil), "social.FollowerHistoryRequest")
+	proto.RegisterType((*FollowerHistoryPoint)(nil), "social.FollowerHistoryPoint")
+	proto.RegisterType((*FollowerHistoryResponse)(nil), "social.FollowerHistoryResponse")
+	proto.RegisterType((*RestoreRequest)(nil), "social.RestoreRequest")
+	proto.RegisterType((*RestoreResponse)(nil), "social.RestoreResponse")
+	proto.RegisterType((*GraphDeletionRequest)(nil), "social.GraphDeletionRequest")
+	proto.RegisterType((*GraphDeletionResponse)(nil), "social.GraphDeletionResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// SocialServerClient is the client API for SocialServer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type SocialServerClient interface {
+	Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	Unfollow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	GetFollow(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	GetFollower(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	GetFollowCount(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error)
+	GetFollowAll(ctx context.Context, in *FollowAllRequest, opts ...grpc.CallOption) (SocialServer_GetFollowAllClient, error)
+	GetFollowerAll(ctx context.Context, in *FollowAllRequest, opts ...grpc.CallOption) (SocialServer_GetFollowerAllClient, error)
+	GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, opts ...grpc.CallOption) (*SuggestionResponse, error)
+	GetCommonFollowers(ctx context.Context, in *CommonRequest, opts ...grpc.CallOption) (*CommonResponse, error)
+	CreateFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*GroupResponse, error)
+	RenameFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	DeleteFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	GetFollowGroups(ctx context.Context, in *GroupListRequest, opts ...grpc.CallOption) (*GroupListResponse, error)
+	AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	UpdateFollowSettings(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error)
+	GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, opts ...grpc.CallOption) (*NotifyFollowersResponse, error)
+	GetFollowStats(ctx context.Context, in *FollowStatsRequest, opts ...grpc.CallOption) (*FollowStatsResponse, error)
+	GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, opts ...grpc.CallOption) (*FollowerHistoryResponse, error)
+	RestoreFollows(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+	DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, opts ...grpc.CallOption) (*GraphDeletionResponse, error)
+}
+
+type socialServerClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewSocialServerClient(cc *grpc.ClientConn) SocialServerClient {
+	return &socialServerClient{cc}
+}
+
+func (c *socialServerClient) Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/Follow", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) Unfollow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/Unfollow", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollow(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollow", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollower(ctx context.Context, in *ListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollower", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowCount(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*CountResponse, error) {
+	out := new(CountResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowCount", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowAll(ctx context.Context, in *FollowAllRequest, opts ...grpc.CallOption) (SocialServer_GetFollowAllClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SocialServer_serviceDesc.Streams[0], "/social.SocialServer/GetFollowAll", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &socialServerGetFollowAllClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SocialServer_GetFollowAllClient interface {
+	Recv() (*FollowAllResponse, error)
+	grpc.ClientStream
+}
+
+type socialServerGetFollowAllClient struct {
+	grpc.ClientStream
+}
+
+func (x *socialServerGetFollowAllClient) Recv() (*FollowAllResponse, error) {
+	m := new(FollowAllResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *socialServerClient) GetFollowerAll(ctx context.Context, in *FollowAllRequest, opts ...grpc.CallOption) (SocialServer_GetFollowerAllClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SocialServer_serviceDesc.Streams[1], "/social.SocialServer/GetFollowerAll", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &socialServerGetFollowerAllClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SocialServer_GetFollowerAllClient interface {
+	Recv() (*FollowAllResponse, error)
+	grpc.ClientStream
+}
+
+type socialServerGetFollowerAllClient struct {
+	grpc.ClientStream
+}
+
+func (x *socialServerGetFollowerAllClient) Recv() (*FollowAllResponse, error) {
+	m := new(FollowAllResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *socialServerClient) GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, opts ...grpc.CallOption) (*SuggestionResponse, error) {
+	out := new(SuggestionResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowSuggestions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetCommonFollowers(ctx context.Context, in *CommonRequest, opts ...grpc.CallOption) (*CommonResponse, error) {
+	out := new(CommonResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetCommonFollowers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) CreateFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*GroupResponse, error) {
+	out := new(GroupResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/CreateFollowGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) RenameFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/RenameFollowGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) DeleteFollowGroup(ctx context.Context, in *GroupRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/DeleteFollowGroup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowGroups(ctx context.Context, in *GroupListRequest, opts ...grpc.CallOption) (*GroupListResponse, error) {
+	out := new(GroupListResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/AddFollowGroupMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/RemoveFollowGroupMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowGroupMembers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) UpdateFollowSettings(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*EmptyResponse, error) {
+	out := new(EmptyResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/UpdateFollowSettings", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, opts ...grpc.CallOption) (*NotifyFollowersResponse, error) {
+	out := new(NotifyFollowersResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetNotifyFollowers", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowStats(ctx context.Context, in *FollowStatsRequest, opts ...grpc.CallOption) (*FollowStatsResponse, error) {
+	out := new(FollowStatsResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, opts ...grpc.CallOption) (*FollowerHistoryResponse, error) {
+	out := new(FollowerHistoryResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/GetFollowerHistory", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) RestoreFollows(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
+	out := new(RestoreResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/RestoreFollows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerClient) DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, opts ...grpc.CallOption) (*GraphDeletionResponse, error) {
+	out := new(GraphDeletionResponse)
+	err := c.cc.Invoke(ctx, "/social.SocialServer/DeleteUserGraph", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SocialServerServer is the server API for SocialServer service.
+type SocialServerServer interface {
+	Follow(context.Context, *FollowRequest) (*EmptyResponse, error)
+	Unfollow(context.Context, *FollowRequest) (*EmptyResponse, error)
+	GetFollow(context.Context, *ListRequest) (*ListResponse, error)
+	GetFollower(context.Context, *ListRequest) (*ListResponse, error)
+	GetFollowCount(context.Context, *CountRequest) (*CountResponse, error)
+	GetFollowAll(*FollowAllRequest, SocialServer_GetFollowAllServer) error
+	GetFollowerAll(*FollowAllRequest, SocialServer_GetFollowerAllServer) error
+	GetFollowSuggestions(context.Context, *SuggestionRequest) (*SuggestionResponse, error)
+	GetCommonFollowers(context.Context, *CommonRequest) (*CommonResponse, error)
+	CreateFollowGroup(context.Context, *GroupRequest) (*GroupResponse, error)
+	RenameFollowGroup(context.Context, *GroupRequest) (*EmptyResponse, error)
+	DeleteFollowGroup(context.Context, *GroupRequest) (*EmptyResponse, error)
+	GetFollowGroups(context.Context, *GroupListRequest) (*GroupListResponse, error)
+	AddFollowGroupMembers(context.Context, *GroupMembersRequest) (*EmptyResponse, error)
+	RemoveFollowGroupMembers(context.Context, *GroupMembersRequest) (*EmptyResponse, error)
+	GetFollowGroupMembers(context.Context, *GroupMemberListRequest) (*ListResponse, error)
+	UpdateFollowSettings(context.Context, *FollowRequest) (*EmptyResponse, error)
+	GetNotifyFollowers(context.Context, *NotifyFollowersRequest) (*NotifyFollowersResponse, error)
+	GetFollowStats(context.Context, *FollowStatsRequest) (*FollowStatsResponse, error)
+	GetFollowerHistory(context.Context, *FollowerHistoryRequest) (*FollowerHistoryResponse, error)
+	RestoreFollows(context.Context, *RestoreRequest) (*RestoreResponse, error)
+	DeleteUserGraph(context.Context, *GraphDeletionRequest) (*GraphDeletionResponse, error)
+}
+
+func RegisterSocialServerServer(s *grpc.Server, srv SocialServerServer) {
+	s.RegisterService(&_SocialServer_serviceDesc, srv)
+}
+
+func _SocialServer_Follow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).Follow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/Follow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).Follow(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_Unfollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).Unfollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/Unfollow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).Unfollow(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollow",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollow(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollower_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollower(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollower",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollower(ctx, req.(*ListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowCount(ctx, req.(*CountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowAll_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FollowAllRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SocialServerServer).GetFollowAll(m, &socialServerGetFollowAllServer{stream})
+}
+
+type SocialServer_GetFollowAllServer interface {
+	Send(*FollowAllResponse) error
+	grpc.ServerStream
+}
+
+type socialServerGetFollowAllServer struct {
+	grpc.ServerStream
+}
+
+func (x *socialServerGetFollowAllServer) Send(m *FollowAllResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SocialServer_GetFollowerAll_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FollowAllRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SocialServerServer).GetFollowerAll(m, &socialServerGetFollowerAllServer{stream})
+}
+
+type SocialServer_GetFollowerAllServer interface {
+	Send(*FollowAllResponse) error
+	grpc.ServerStream
+}
+
+type socialServerGetFollowerAllServer struct {
+	grpc.ServerStream
+}
+
+func (x *socialServerGetFollowerAllServer) Send(m *FollowAllResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SocialServer_GetFollowSuggestions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowSuggestions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowSuggestions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowSuggestions(ctx, req.(*SuggestionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetCommonFollowers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetCommonFollowers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetCommonFollowers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetCommonFollowers(ctx, req.(*CommonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_CreateFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).CreateFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/CreateFollowGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).CreateFollowGroup(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_RenameFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).RenameFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/RenameFollowGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).RenameFollowGroup(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_DeleteFollowGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).DeleteFollowGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/DeleteFollowGroup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).DeleteFollowGroup(ctx, req.(*GroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowGroups(ctx, req.(*GroupListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_AddFollowGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).AddFollowGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/AddFollowGroupMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).AddFollowGroupMembers(ctx, req.(*GroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_RemoveFollowGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).RemoveFollowGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/RemoveFollowGroupMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).RemoveFollowGroupMembers(ctx, req.(*GroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GroupMemberListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowGroupMembers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowGroupMembers(ctx, req.(*GroupMemberListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_UpdateFollowSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).UpdateFollowSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/UpdateFollowSettings",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).UpdateFollowSettings(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetNotifyFollowers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotifyFollowersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetNotifyFollowers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetNotifyFollowers",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetNotifyFollowers(ctx, req.(*NotifyFollowersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowStats(ctx, req.(*FollowStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_GetFollowerHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowerHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).GetFollowerHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/GetFollowerHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).GetFollowerHistory(ctx, req.(*FollowerHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_RestoreFollows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).RestoreFollows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/RestoreFollows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).RestoreFollows(ctx, req.(*RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SocialServer_DeleteUserGraph_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GraphDeletionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SocialServerServer).DeleteUserGraph(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/social.SocialServer/DeleteUserGraph",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SocialServerServer).DeleteUserGraph(ctx, req.(*GraphDeletionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _SocialServer_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "social.SocialServer",
+	HandlerType: (*SocialServerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Follow",
+			Handler:    _SocialServer_Follow_Handler,
+		},
+		{
+			MethodName: "Unfollow",
+			Handler:    _SocialServer_Unfollow_Handler,
+		},
+		{
+			MethodName: "GetFollow",
+			Handler:    _SocialServer_GetFollow_Handler,
+		},
+		{
+			MethodName: "GetFollower",
+			Handler:    _SocialServer_GetFollower_Handler,
+		},
+		{
+			MethodName: "GetFollowCount",
+			Handler:    _SocialServer_GetFollowCount_Handler,
+		},
+		{
+			MethodName: "GetFollowSuggestions",
+			Handler:    _SocialServer_GetFollowSuggestions_Handler,
+		},
+		{
+			MethodName: "GetCommonFollowers",
+			Handler:    _SocialServer_GetCommonFollowers_Handler,
+		},
+		{
+			MethodName: "CreateFollowGroup",
+			Handler:    _SocialServer_CreateFollowGroup_Handler,
+		},
+		{
+			MethodName: "RenameFollowGroup",
+			Handler:    _SocialServer_RenameFollowGroup_Handler,
+		},
+		{
+			MethodName: "DeleteFollowGroup",
+			Handler:    _SocialServer_DeleteFollowGroup_Handler,
+		},
+		{
+			MethodName: "GetFollowGroups",
+			Handler:    _SocialServer_GetFollowGroups_Handler,
+		},
+		{
+			MethodName: "AddFollowGroupMembers",
+			Handler:    _SocialServer_AddFollowGroupMembers_Handler,
+		},
+		{
+			MethodName: "RemoveFollowGroupMembers",
+			Handler:    _SocialServer_RemoveFollowGroupMembers_Handler,
+		},
+		{
+			MethodName: "GetFollowGroupMembers",
+			Handler:    _SocialServer_GetFollowGroupMembers_Handler,
+		},
+		{
+			MethodName: "UpdateFollowSettings",
+			Handler:    _SocialServer_UpdateFollowSettings_Handler,
+		},
+		{
+			MethodName: "GetNotifyFollowers",
+			Handler:    _SocialServer_GetNotifyFollowers_Handler,
+		},
+		{
+			MethodName: "GetFollowStats",
+			Handler:    _SocialServer_GetFollowStats_Handler,
+		},
+		{
+			MethodName: "GetFollowerHistory",
+			Handler:    _SocialServer_GetFollowerHistory_Handler,
+		},
+		{
+			MethodName: "RestoreFollows",
+			Handler:    _SocialServer_RestoreFollows_Handler,
+		},
+		{
+			MethodName: "DeleteUserGraph",
+			Handler:    _SocialServer_DeleteUserGraph_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetFollowAll",
+			Handler:       _SocialServer_GetFollowAll_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetFollowerAll",
+			Handler:       _SocialServer_GetFollowerAll_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/social/social.proto",
 }
 
 func init() { proto.RegisterFile("proto/social/social.proto", fileDescriptor_social_6f0a2fe2d7e7fb81) }
 
 var fileDescriptor_social_6f0a2fe2d7e7fb81 = []byte{
-	// 478 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x54, 0x4d, 0x6f, 0xd3, 0x40,
-	0x10, 0x95, 0xe3, 0xe2, 0xa6, 0x13, 0xa7, 0x2d, 0xc3, 0x97, 0x13, 0x10, 0x04, 0x0b, 0x44, 0x4e,
-	0x05, 0xb5, 0x52, 0x85, 0x84, 0x7a, 0x08, 0xd0, 0x56, 0x91, 0xe0, 0xe2, 0xc2, 0x01, 0x84, 0x14,
-	0xb9, 0xf1, 0x84, 0x5a, 0xda, 0x64, 0xdd, 0xdd, 0x4d, 0x51, 0x7e, 0x1e, 0xff, 0x0c, 0x65, 0x77,
-	0xfd, 0x95, 0x00, 0x52, 0xe0, 0x94, 0x99, 0x97, 0x79, 0x6f, 0xde, 0xcc, 0xac, 0x0c, 0x9d, 0x4c,
-	0x70, 0xc5, 0x5f, 0x4a, 0x3e, 0x4e, 0x63, 0x66, 0x7f, 0x0e, 0x34, 0x86, 0x9e, 0xc9, 0xc2, 0x6f,
-	0x00, 0x67, 0x9c, 0x31, 0xfe, 0x63, 0xa8, 0x68, 0x8a, 0xfb, 0xe0, 0xce, 0xd3, 0x24, 0x70, 0x7a,
-	0x4e, 0xdf, 0x8d, 0x96, 0x21, 0x3e, 0x84, 0x1d, 0x15, 0x8b, 0xef, 0xa4, 0x46, 0x69, 0x12, 0x34,
-	0x34, 0xde, 0x34, 0xc0, 0x30, 0xc1, 0x27, 0xd0, 0x9a, 0x68, 0xf2, 0x48, 0x2d, 0x32, 0x0a, 0xdc,
-	0x9e, 0xd3, 0xbf, 0x15, 0x81, 0x81, 0x3e, 0x2d, 0x32, 0x0a, 0xdf, 0x43, 0xdb, 0xa8, 0x47, 0x74,
-	0x3d, 0x27, 0xa9, 0xf0, 0xa8, 0x60, 0xa4, 0x8a, 0xa6, 0xba, 0x51, 0xeb, 0x10, 0x0f, 0xac, 0xb5,
-	0xd2, 0x49, 0xae, 0xb2, 0x8c, 0xc3, 0x3d, 0x68, 0x9f, 0x4e, 0x33, 0xb5, 0x88, 0x48, 0x66, 0x7c,
-	0x26, 0x29, 0x3c, 0x83, 0xbd, 0xcf, 0xb3, 0xc9, 0xff, 0x0b, 0x5f, 0x43, 0xeb, 0x43, 0x2a, 0x55,
-	0xae, 0xb1, 0x3e, 0xfd, 0x03, 0xd8, 0x66, 0xb1, 0xac, 0xcc, 0xee, 0x2d, 0xd3, 0x61, 0x82, 0xf7,
-	0xc1, 0xe3, 0x93, 0x89, 0x24, 0xa5, 0x87, 0x76, 0x23, 0x9b, 0xad, 0x6e, 0x64, 0x6b, 0x6d, 0x23,
-	0x27, 0xe0, 0x9b, 0x96, 0x66, 0x14, 0x44, 0xd8, 0x9a, 0xa7, 0x89, 0x0c, 0x9c, 0x9e, 0xdb, 0x77,
-	0x23, 0x1d, 0x63, 0x07, 0x9a, 0x57, 0xb1, 0x1c, 0x4d, 0xb9, 0x20, 0xdd, 0xb6, 0x19, 0x6d, 0x5f,
-	0xc5, 0xf2, 0x23, 0x17, 0x14, 0x0e, 0xc0, 0x7f, 0xc7, 0xe7, 0xb3, 0xbf, 0x58, 0x5e, 0x71, 0xd0,
-	0x58, 0x73, 0xf0, 0x05, 0xda, 0x56, 0xc2, 0x5a, 0x78, 0x0a, 0xbe, 0x65, 0x8c, 0x97, 0xb8, 0x15,
-	0xb3, 0x2a, 0xba, 0x14, 0x9f, 0xc3, 0xae, 0x49, 0x49, 0xd8, 0x22, 0xb3, 0x8e, 0x76, 0x8e, 0xea,
-	0xb2, 0xf0, 0x19, 0xec, 0x9b, 0x4d, 0x0f, 0x18, 0xfb, 0xa3, 0xc3, 0xf0, 0x05, 0xdc, 0xae, 0x54,
-	0xad, 0xec, 0xa1, 0x51, 0xee, 0xe1, 0xf0, 0xa7, 0x0b, 0xfe, 0x85, 0x3e, 0xe0, 0x05, 0x89, 0x1b,
-	0x12, 0x78, 0x0c, 0x9e, 0x61, 0xe2, 0xbd, 0xfa, 0x65, 0x6d, 0xb3, 0x6e, 0x01, 0xd7, 0xde, 0x0b,
-	0xbe, 0x86, 0x66, 0xfe, 0x5e, 0x36, 0x64, 0x1e, 0xc3, 0xce, 0x39, 0x29, 0xdb, 0xf4, 0x4e, 0x5e,
-	0x53, 0x79, 0x34, 0xdd, 0xbb, 0x75, 0xb0, 0xe8, 0xd8, 0x2a, 0x78, 0x24, 0x36, 0x61, 0x9e, 0xc0,
-	0x6e, 0xc1, 0x34, 0xcb, 0x2f, 0xea, 0xaa, 0x97, 0x2f, 0x0d, 0xd7, 0x8f, 0x79, 0x0a, 0x7e, 0x41,
-	0x1f, 0x30, 0x86, 0x41, 0x7d, 0xdc, 0xf2, 0x30, 0xdd, 0xce, 0x6f, 0xfe, 0x31, 0x22, 0xaf, 0x1c,
-	0x3c, 0xaf, 0xb8, 0x20, 0xf1, 0xef, 0x42, 0x6f, 0x1f, 0x7f, 0x7d, 0x24, 0xb2, 0x71, 0xfe, 0x09,
-	0xca, 0x2e, 0xdf, 0x98, 0x68, 0x24, 0x49, 0xdc, 0xa4, 0x63, 0xba, 0xf4, 0xf4, 0xe7, 0xe8, 0xe8,
-	0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x5a, 0x49, 0x71, 0x65, 0xab, 0x04, 0x00, 0x00,
+	// 1378 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x58, 0xdd, 0x6e, 0x13, 0x47,
+	0x14, 0x96, 0x7f, 0x13, 0x1f, 0x3b, 0x09, 0x19, 0x9c, 0xc4, 0x59, 0x02, 0x09, 0xa3, 0x56, 0x75,
+	0x55, 0x89, 0x22, 0xa8, 0x50, 0x05, 0x42, 0x25, 0x05, 0x92, 0x06, 0x1a, 0xa8, 0x36, 0xf5, 0x05,
+	0xbd, 0xb1, 0x16, 0xef, 0xd8, 0xac, 0xb4, 0xbb, 0xb3, 0xec, 0x8c, 0xd3, 0xfa, 0xaa, 0x2f, 0xd1,
+	0x17, 0xe8, 0x63, 0xf4, 0xa6, 0xcf, 0x56, 0xcd, 0xcf, 0xce, 0xce, 0xda, 0x6b, 0xb7, 0x09, 0xbd,
+	0xf2, 0x9c, 0xb3, 0x67, 0xbe, 0xf3, 0x37, 0xe7, 0x47, 0x86, 0xfd, 0x24, 0xa5, 0x9c, 0x7e, 0xcd,
+	0xe8, 0x28, 0xf0, 0x42, 0xfd, 0x73, 0x4f, 0xf2, 0x50, 0x53, 0x51, 0xf8, 0xef, 0x0a, 0xc0, 0x09,
+	0x0d, 0x43, 0xfa, 0xeb, 0x19, 0x27, 0x11, 0xba, 0x01, 0xb5, 0x69, 0xe0, 0xf7, 0x2a, 0x47, 0x95,
+	0x7e, 0xcd, 0x15, 0x47, 0x74, 0x0b, 0x5a, 0xdc, 0x4b, 0x27, 0x84, 0x0f, 0x03, 0xbf, 0x57, 0x95,
+	0xfc, 0x75, 0xc5, 0x38, 0xf3, 0xd1, 0x21, 0xb4, 0xc7, 0xf2, 0xf2, 0x90, 0xcf, 0x12, 0xd2, 0xab,
+	0x1d, 0x55, 0xfa, 0x0d, 0x17, 0x14, 0xeb, 0xe7, 0x59, 0x42, 0xd0, 0x2e, 0x34, 0x63, 0xca, 0x83,
+	0xf1, 0xac, 0x57, 0x97, 0xdf, 0x34, 0x85, 0x7a, 0xb0, 0xc6, 0x12, 0x22, 0x2c, 0xe8, 0x35, 0x8e,
+	0x2a, 0xfd, 0x75, 0x37, 0x23, 0xc5, 0x0d, 0x46, 0xa7, 0xe9, 0x88, 0xf4, 0x9a, 0x47, 0x95, 0x7e,
+	0xcb, 0xd5, 0x94, 0xe0, 0x8f, 0xc2, 0x80, 0xc4, 0xbc, 0xb7, 0xa6, 0xf8, 0x8a, 0xc2, 0x2f, 0x60,
+	0x43, 0xd9, 0xef, 0x92, 0x8f, 0x53, 0xc2, 0x38, 0x7a, 0x68, 0x6c, 0x0a, 0x38, 0x89, 0xa4, 0x2b,
+	0xed, 0x07, 0xe8, 0x9e, 0xf6, 0x3e, 0xf7, 0x35, 0xb3, 0x53, 0x9c, 0xf1, 0x16, 0x6c, 0xbc, 0x8c,
+	0x12, 0x3e, 0x73, 0x09, 0x4b, 0x68, 0xcc, 0x08, 0x3e, 0x81, 0xad, 0x41, 0x3c, 0xfe, 0x74, 0xe0,
+	0x8f, 0xd0, 0xfe, 0x31, 0x60, 0x3c, 0xc3, 0x58, 0x8c, 0xef, 0x1e, 0xac, 0x85, 0x1e, 0xb3, 0xa2,
+	0xdb, 0x14, 0xe4, 0x99, 0x2f, 0x1c, 0xa6, 0xe3, 0x31, 0x23, 0x5c, 0x86, 0xb5, 0xe6, 0x6a, 0x6a,
+	0x3e, 0xe6, 0xf5, 0xf9, 0x98, 0xe3, 0xa7, 0xd0, 0x51, 0x2a, 0x95, 0x2b, 0x08, 0x41, 0x7d, 0x1a,
+	0xf8, 0xac, 0x57, 0x39, 0xaa, 0xf5, 0x6b, 0xae, 0x3c, 0xa3, 0x7d, 0x58, 0xff, 0xe0, 0xb1, 0x61,
+	0x44, 0x53, 0x22, 0xd5, 0xae, 0xbb, 0x6b, 0x1f, 0x3c, 0x76, 0x4e, 0x53, 0x82, 0x8f, 0xa1, 0xf3,
+	0x9c, 0x4e, 0xe3, 0x15, 0x26, 0xcf, 0x59, 0x50, 0x5d, 0xb0, 0xe0, 0x1d, 0x6c, 0x68, 0x08, 0x6d,
+	0xc2, 0x5d, 0xe8, 0xe8, 0x1b, 0x23, 0xc1, 0xd7, 0x60, 0x1a, 0x45, 0x8a, 0xa2, 0xcf, 0x61, 0x53,
+	0x91, 0x24, 0xd5, 0x42, 0x2a, 0x1c, 0x1b, 0x19, 0x57, 0x8a, 0xe1, 0xcf, 0xe0, 0x86, 0x8a, 0xf4,
+	0x71, 0x18, 0x2e, 0xb5, 0x10, 0x7f, 0x01, 0xdb, 0x96, 0xd4, 0x5c, 0x1c, 0xaa, 0x79, 0x1c, 0xf0,
+	0x13, 0xd8, 0xbe, 0x98, 0x4e, 0x26, 0x84, 0xf1, 0x80, 0xc6, 0xcb, 0x3d, 0xee, 0x42, 0x23, 0x0c,
+	0xa2, 0x20, 0xb3, 0x49, 0x11, 0xf8, 0x11, 0x40, 0x7e, 0xb9, 0xe4, 0xd6, 0x2e, 0x34, 0xa3, 0x29,
+	0x9f, 0x7a, 0x61, 0x96, 0x59, 0x45, 0xe1, 0x57, 0x80, 0x6c, 0xa5, 0xda, 0xbc, 0x6f, 0xa0, 0xcd,
+	0x0c, 0x57, 0x65, 0xcb, 0x7a, 0x5e, 0xd6, 0x05, 0x5b, 0x0c, 0x8f, 0x44, 0xa8, 0xa3, 0x28, 0x37,
+	0xfe, 0x36, 0xc0, 0x65, 0x40, 0x44, 0x14, 0x73, 0x6b, 0x5a, 0x8a, 0x33, 0x08, 0x7c, 0xf1, 0x59,
+	0x97, 0xf3, 0xd4, 0xbc, 0x38, 0x5d, 0xe0, 0x03, 0xdb, 0xd1, 0x9a, 0xed, 0xe8, 0x63, 0xd8, 0xcc,
+	0x94, 0xac, 0x78, 0x53, 0x5d, 0x68, 0x70, 0xca, 0x8d, 0xb7, 0x8a, 0xc0, 0x3e, 0xb4, 0x55, 0x2a,
+	0x4e, 0x53, 0x3a, 0x4d, 0xc4, 0xc3, 0x9b, 0x88, 0xc3, 0xd0, 0x18, 0xb7, 0x26, 0xe9, 0x33, 0x3f,
+	0x0b, 0x60, 0x35, 0x0f, 0x20, 0x82, 0x7a, 0xec, 0x45, 0xaa, 0xaf, 0xb4, 0x5c, 0x79, 0x16, 0x5a,
+	0x46, 0x3c, 0x88, 0xd4, 0xc3, 0xaf, 0xb9, 0x8a, 0xc0, 0x6f, 0xa1, 0x23, 0xf1, 0x97, 0xa7, 0xd0,
+	0x56, 0x5c, 0x2d, 0x2a, 0x2e, 0x51, 0x83, 0x1f, 0xc3, 0x86, 0x06, 0xd4, 0x1e, 0x7f, 0x09, 0x0d,
+	0x29, 0xaf, 0xeb, 0xfe, 0x66, 0xb1, 0xee, 0x95, 0xac, 0x92, 0x10, 0x6f, 0x54, 0xd2, 0x2b, 0x0b,
+	0x1f, 0x3f, 0x83, 0x6d, 0x4b, 0x4a, 0x6b, 0xf9, 0x0a, 0x9a, 0x12, 0x23, 0xcb, 0x7f, 0xa9, 0x1a,
+	0x2d, 0x82, 0x47, 0x70, 0x53, 0x32, 0xce, 0x49, 0xf4, 0x9e, 0xa4, 0xec, 0x5a, 0xbe, 0x1f, 0x42,
+	0x3b, 0x92, 0xd7, 0x87, 0x32, 0x9f, 0x35, 0x99, 0x4f, 0x50, 0xac, 0x81, 0xa8, 0x90, 0x4b, 0xd8,
+	0xb5, 0x94, 0xac, 0xee, 0x65, 0x2b, 0xf4, 0x58, 0x6d, 0xae, 0xb6, 0xa4, 0xcd, 0xd5, 0xed, 0x36,
+	0x87, 0xff, 0xa8, 0xc0, 0xee, 0x1b, 0x39, 0x2c, 0x4e, 0x74, 0x03, 0x58, 0xe1, 0x60, 0x3e, 0x66,
+	0xaa, 0x85, 0x31, 0x73, 0x17, 0x3a, 0x7a, 0xae, 0x0c, 0x69, 0x1c, 0xce, 0xa4, 0xea, 0x75, 0xb7,
+	0xad, 0x79, 0x6f, 0xe3, 0x70, 0x66, 0x1b, 0x56, 0x2f, 0x18, 0x66, 0x4a, 0xa1, 0x61, 0x97, 0xc2,
+	0xef, 0xb0, 0xb7, 0x60, 0x95, 0xce, 0xdd, 0x7d, 0x68, 0x65, 0xbd, 0x6a, 0xa1, 0x7c, 0xad, 0xe9,
+	0x90, 0x0b, 0xad, 0xe8, 0xc2, 0xc2, 0xac, 0x98, 0xfc, 0x66, 0xc7, 0x4b, 0x90, 0x67, 0xbe, 0x68,
+	0x1e, 0x0a, 0xec, 0x82, 0x7b, 0x7c, 0x45, 0x48, 0x10, 0xd4, 0xc7, 0x29, 0x8d, 0x24, 0x6e, 0xcb,
+	0x95, 0x67, 0xb4, 0x09, 0x55, 0x4e, 0xf5, 0x33, 0xaf, 0x72, 0x8a, 0x5f, 0x01, 0xe4, 0x58, 0x02,
+	0xc3, 0xf7, 0x66, 0x12, 0xa3, 0xe5, 0x8a, 0xa3, 0x08, 0xeb, 0xc4, 0x0b, 0x62, 0x62, 0x46, 0x93,
+	0xa2, 0x04, 0x76, 0x48, 0x59, 0xd6, 0x24, 0xe4, 0x19, 0x7f, 0x07, 0x37, 0x0b, 0x76, 0xe9, 0xa0,
+	0xf4, 0xa1, 0xc1, 0x04, 0xa3, 0x3c, 0x20, 0x42, 0xd6, 0x55, 0x02, 0x38, 0x81, 0xdd, 0x2c, 0xa6,
+	0x3f, 0x04, 0x8c, 0xd3, 0x74, 0xb6, 0xdc, 0xb9, 0x23, 0x68, 0x4f, 0x52, 0x2f, 0x9e, 0x86, 0x5e,
+	0x1a, 0xf0, 0x99, 0xf6, 0xd1, 0x66, 0x19, 0xf7, 0x6b, 0x0b, 0xee, 0xd7, 0x8d, 0xfb, 0x03, 0xe8,
+	0xce, 0x69, 0xfc, 0x89, 0x06, 0x31, 0x17, 0x6e, 0x27, 0x24, 0x0d, 0xa8, 0xaf, 0x63, 0xa1, 0xa9,
+	0xff, 0x3a, 0xa2, 0xde, 0xc2, 0xde, 0x82, 0x23, 0xa6, 0xc7, 0x37, 0x13, 0xa1, 0x22, 0x0b, 0xc7,
+	0x41, 0x31, 0x1c, 0x45, 0x3b, 0x5c, 0x2d, 0x8b, 0xdf, 0xc1, 0xa6, 0x4b, 0x04, 0x9f, 0x5c, 0x7f,
+	0x26, 0x8b, 0xe7, 0xcc, 0x82, 0x78, 0x44, 0xb2, 0xce, 0x2e, 0x09, 0xfc, 0x1a, 0xb6, 0x0c, 0xb4,
+	0xb6, 0x31, 0x9f, 0x10, 0x79, 0x83, 0x6f, 0x65, 0x1b, 0xdf, 0xca, 0xcd, 0xa1, 0x0f, 0xdd, 0xd3,
+	0xd4, 0x4b, 0x3e, 0xbc, 0x20, 0x21, 0x59, 0x39, 0x4f, 0xf1, 0x5f, 0x15, 0xd8, 0x99, 0x13, 0xd5,
+	0xda, 0x4b, 0x1f, 0xb2, 0x4f, 0xe3, 0x4c, 0x99, 0x3c, 0x8b, 0xf5, 0x51, 0xb9, 0xc6, 0xb4, 0x3b,
+	0x19, 0x89, 0x0e, 0xec, 0x22, 0x54, 0x05, 0x9d, 0x33, 0x44, 0x66, 0x39, 0x4d, 0x82, 0x11, 0xd3,
+	0x45, 0xad, 0xa9, 0x7c, 0xa8, 0x34, 0xad, 0xa1, 0x22, 0xb8, 0x91, 0xe4, 0xae, 0x29, 0xae, 0x24,
+	0x1e, 0xfc, 0xd9, 0x81, 0xce, 0x85, 0xcc, 0xda, 0x05, 0x49, 0x2f, 0x49, 0x8a, 0x1e, 0x41, 0x53,
+	0xa5, 0x0f, 0xed, 0x14, 0xd3, 0xa9, 0xfd, 0x77, 0x0c, 0xbb, 0xb0, 0x62, 0xa2, 0x6f, 0x61, 0x3d,
+	0x5b, 0x31, 0xaf, 0x78, 0xf3, 0x11, 0xb4, 0x4e, 0x09, 0xd7, 0x4a, 0xcd, 0x88, 0xb0, 0x7a, 0xb3,
+	0xd3, 0x2d, 0x32, 0x8d, 0xc6, 0xb6, 0xb9, 0x47, 0xd2, 0xab, 0xdc, 0x7c, 0x0a, 0x9b, 0xe6, 0xa6,
+	0xda, 0xd7, 0x8c, 0x9c, 0xbd, 0x2c, 0x3a, 0x3b, 0x73, 0x5c, 0x7d, 0xfd, 0x25, 0x74, 0xcc, 0xf5,
+	0xe3, 0x30, 0x44, 0xbd, 0xa2, 0xbb, 0xf9, 0x2e, 0xe7, 0xec, 0x97, 0x7c, 0x51, 0x20, 0xf7, 0x2b,
+	0xe8, 0xd4, 0xb2, 0x82, 0xa4, 0x9f, 0x00, 0x74, 0x0e, 0x5d, 0x03, 0x94, 0x6f, 0x56, 0x0c, 0xed,
+	0x97, 0xac, 0x5b, 0x1a, 0xcf, 0x29, 0xfb, 0xa4, 0xdd, 0x7b, 0x0e, 0xe8, 0x94, 0x70, 0xb5, 0x22,
+	0x99, 0xb9, 0x80, 0xac, 0x58, 0x58, 0x0b, 0x9a, 0xb3, 0x3b, 0xcf, 0xd6, 0x20, 0xcf, 0x60, 0xfb,
+	0x79, 0x4a, 0x3c, 0x4e, 0xec, 0x75, 0xc9, 0x44, 0xd9, 0xde, 0x6e, 0x9c, 0x9d, 0x39, 0x6e, 0x8e,
+	0xe0, 0x12, 0xb1, 0xbd, 0x5c, 0x01, 0xa1, 0xf8, 0xb0, 0x9e, 0xc1, 0xb6, 0xac, 0xc8, 0xeb, 0x23,
+	0x9c, 0xc0, 0x96, 0x89, 0xac, 0x94, 0x67, 0x79, 0x8e, 0xe6, 0x97, 0x22, 0x67, 0xbf, 0xe4, 0x8b,
+	0xc6, 0x79, 0x0d, 0x3b, 0xc7, 0xbe, 0x6f, 0xe1, 0xe8, 0x25, 0x07, 0xdd, 0x2a, 0xdc, 0x29, 0xae,
+	0x3e, 0xcb, 0x8c, 0x7a, 0x03, 0x3d, 0x97, 0x44, 0xf4, 0x92, 0xfc, 0x4f, 0x78, 0xe7, 0xb0, 0x53,
+	0x74, 0x32, 0x03, 0xbb, 0x53, 0x02, 0xf6, 0xef, 0xc5, 0xf5, 0x02, 0xba, 0x83, 0xc4, 0x37, 0x99,
+	0xbf, 0x20, 0x9c, 0x07, 0xf1, 0x84, 0x5d, 0xb1, 0x29, 0x0c, 0xe4, 0x23, 0x9c, 0x5b, 0x4e, 0x72,
+	0x8b, 0xca, 0x77, 0x29, 0xe7, 0x70, 0xe9, 0x77, 0x0d, 0x7b, 0x66, 0xd5, 0x9c, 0x1c, 0xed, 0xc8,
+	0x59, 0x9c, 0xe1, 0x06, 0xee, 0x56, 0xe9, 0xb7, 0x82, 0x85, 0x73, 0xa3, 0x2e, 0xb7, 0xb0, 0x7c,
+	0xfa, 0x3b, 0x87, 0x4b, 0xbf, 0x6b, 0xd8, 0x63, 0x33, 0x1e, 0x4f, 0xf4, 0x10, 0x30, 0x25, 0x56,
+	0x1c, 0x9b, 0xce, 0xde, 0x02, 0xdf, 0x3c, 0x90, 0x2d, 0xf5, 0xee, 0x07, 0x8c, 0xa4, 0x72, 0x30,
+	0xa1, 0x83, 0x3c, 0x95, 0x8b, 0x23, 0xcd, 0xb9, 0xbd, 0xe4, 0xab, 0xc2, 0xfb, 0xfe, 0xce, 0x2f,
+	0x07, 0x69, 0x32, 0xca, 0xfe, 0x78, 0x49, 0xde, 0x3f, 0x51, 0xa7, 0x21, 0x23, 0xe9, 0x65, 0x30,
+	0x22, 0xef, 0x9b, 0xf2, 0x4f, 0x98, 0x87, 0xff, 0x0c, 0x00, 0x97, 0xec, 0x73, 0x6a, 0xa1, 0x11,
+	0x00, 0x00,
 }
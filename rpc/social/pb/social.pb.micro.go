@@ -41,6 +41,21 @@ type SocialServerService interface {
 	GetFollowCount(ctx context.Context, in *CountRequest, opts ...client.CallOption) (*CountResponse, error)
 	GetFollowAll(ctx context.Context, in *FollowAllRequest, opts ...client.CallOption) (SocialServer_GetFollowAllService, error)
 	GetFollowerAll(ctx context.Context, in *FollowAllRequest, opts ...client.CallOption) (SocialServer_GetFollowerAllService, error)
+	GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, opts ...client.CallOption) (*SuggestionResponse, error)
+	GetCommonFollowers(ctx context.Context, in *CommonRequest, opts ...client.CallOption) (*CommonResponse, error)
+	CreateFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*GroupResponse, error)
+	RenameFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	DeleteFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	GetFollowGroups(ctx context.Context, in *GroupListRequest, opts ...client.CallOption) (*GroupListResponse, error)
+	AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, opts ...client.CallOption) (*ListResponse, error)
+	UpdateFollowSettings(ctx context.Context, in *FollowRequest, opts ...client.CallOption) (*EmptyResponse, error)
+	GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, opts ...client.CallOption) (*NotifyFollowersResponse, error)
+	GetFollowStats(ctx context.Context, in *FollowStatsRequest, opts ...client.CallOption) (*FollowStatsResponse, error)
+	GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, opts ...client.CallOption) (*FollowerHistoryResponse, error)
+	RestoreFollows(ctx context.Context, in *RestoreRequest, opts ...client.CallOption) (*RestoreResponse, error)
+	DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, opts ...client.CallOption) (*GraphDeletionResponse, error)
 }
 
 type socialServerService struct {
@@ -199,6 +214,156 @@ func (x *socialServerServiceGetFollowerAll) Recv() (*FollowAllResponse, error) {
 	return m, nil
 }
 
+func (c *socialServerService) GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, opts ...client.CallOption) (*SuggestionResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetFollowSuggestions", in)
+	out := new(SuggestionResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetCommonFollowers(ctx context.Context, in *CommonRequest, opts ...client.CallOption) (*CommonResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetCommonFollowers", in)
+	out := new(CommonResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) CreateFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*GroupResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.CreateFollowGroup", in)
+	out := new(GroupResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) RenameFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.RenameFollowGroup", in)
+	out := new(EmptyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) DeleteFollowGroup(ctx context.Context, in *GroupRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.DeleteFollowGroup", in)
+	out := new(EmptyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetFollowGroups(ctx context.Context, in *GroupListRequest, opts ...client.CallOption) (*GroupListResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetFollowGroups", in)
+	out := new(GroupListResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.AddFollowGroupMembers", in)
+	out := new(EmptyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.RemoveFollowGroupMembers", in)
+	out := new(EmptyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, opts ...client.CallOption) (*ListResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetFollowGroupMembers", in)
+	out := new(ListResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) UpdateFollowSettings(ctx context.Context, in *FollowRequest, opts ...client.CallOption) (*EmptyResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.UpdateFollowSettings", in)
+	out := new(EmptyResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, opts ...client.CallOption) (*NotifyFollowersResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetNotifyFollowers", in)
+	out := new(NotifyFollowersResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetFollowStats(ctx context.Context, in *FollowStatsRequest, opts ...client.CallOption) (*FollowStatsResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetFollowStats", in)
+	out := new(FollowStatsResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, opts ...client.CallOption) (*FollowerHistoryResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.GetFollowerHistory", in)
+	out := new(FollowerHistoryResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) RestoreFollows(ctx context.Context, in *RestoreRequest, opts ...client.CallOption) (*RestoreResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.RestoreFollows", in)
+	out := new(RestoreResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *socialServerService) DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, opts ...client.CallOption) (*GraphDeletionResponse, error) {
+	req := c.c.NewRequest(c.name, "SocialServer.DeleteUserGraph", in)
+	out := new(GraphDeletionResponse)
+	err := c.c.Call(ctx, req, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for SocialServer service
 
 type SocialServerHandler interface {
@@ -209,6 +374,21 @@ type SocialServerHandler interface {
 	GetFollowCount(context.Context, *CountRequest, *CountResponse) error
 	GetFollowAll(context.Context, *FollowAllRequest, SocialServer_GetFollowAllStream) error
 	GetFollowerAll(context.Context, *FollowAllRequest, SocialServer_GetFollowerAllStream) error
+	GetFollowSuggestions(context.Context, *SuggestionRequest, *SuggestionResponse) error
+	GetCommonFollowers(context.Context, *CommonRequest, *CommonResponse) error
+	CreateFollowGroup(context.Context, *GroupRequest, *GroupResponse) error
+	RenameFollowGroup(context.Context, *GroupRequest, *EmptyResponse) error
+	DeleteFollowGroup(context.Context, *GroupRequest, *EmptyResponse) error
+	GetFollowGroups(context.Context, *GroupListRequest, *GroupListResponse) error
+	AddFollowGroupMembers(context.Context, *GroupMembersRequest, *EmptyResponse) error
+	RemoveFollowGroupMembers(context.Context, *GroupMembersRequest, *EmptyResponse) error
+	GetFollowGroupMembers(context.Context, *GroupMemberListRequest, *ListResponse) error
+	UpdateFollowSettings(context.Context, *FollowRequest, *EmptyResponse) error
+	GetNotifyFollowers(context.Context, *NotifyFollowersRequest, *NotifyFollowersResponse) error
+	GetFollowStats(context.Context, *FollowStatsRequest, *FollowStatsResponse) error
+	GetFollowerHistory(context.Context, *FollowerHistoryRequest, *FollowerHistoryResponse) error
+	RestoreFollows(context.Context, *RestoreRequest, *RestoreResponse) error
+	DeleteUserGraph(context.Context, *GraphDeletionRequest, *GraphDeletionResponse) error
 }
 
 func RegisterSocialServerHandler(s server.Server, hdlr SocialServerHandler, opts ...server.HandlerOption) error {
@@ -220,6 +400,21 @@ func RegisterSocialServerHandler(s server.Server, hdlr SocialServerHandler, opts
 		GetFollowCount(ctx context.Context, in *CountRequest, out *CountResponse) error
 		GetFollowAll(ctx context.Context, stream server.Stream) error
 		GetFollowerAll(ctx context.Context, stream server.Stream) error
+		GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, out *SuggestionResponse) error
+		GetCommonFollowers(ctx context.Context, in *CommonRequest, out *CommonResponse) error
+		CreateFollowGroup(ctx context.Context, in *GroupRequest, out *GroupResponse) error
+		RenameFollowGroup(ctx context.Context, in *GroupRequest, out *EmptyResponse) error
+		DeleteFollowGroup(ctx context.Context, in *GroupRequest, out *EmptyResponse) error
+		GetFollowGroups(ctx context.Context, in *GroupListRequest, out *GroupListResponse) error
+		AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, out *EmptyResponse) error
+		RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, out *EmptyResponse) error
+		GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, out *ListResponse) error
+		UpdateFollowSettings(ctx context.Context, in *FollowRequest, out *EmptyResponse) error
+		GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, out *NotifyFollowersResponse) error
+		GetFollowStats(ctx context.Context, in *FollowStatsRequest, out *FollowStatsResponse) error
+		GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, out *FollowerHistoryResponse) error
+		RestoreFollows(ctx context.Context, in *RestoreRequest, out *RestoreResponse) error
+		DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, out *GraphDeletionResponse) error
 	}
 	type SocialServer struct {
 		socialServer
@@ -321,3 +516,63 @@ func (x *socialServerGetFollowerAllStream) RecvMsg(m interface{}) error {
 func (x *socialServerGetFollowerAllStream) Send(m *FollowAllResponse) error {
 	return x.stream.Send(m)
 }
+
+func (h *socialServerHandler) GetFollowSuggestions(ctx context.Context, in *SuggestionRequest, out *SuggestionResponse) error {
+	return h.SocialServerHandler.GetFollowSuggestions(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetCommonFollowers(ctx context.Context, in *CommonRequest, out *CommonResponse) error {
+	return h.SocialServerHandler.GetCommonFollowers(ctx, in, out)
+}
+
+func (h *socialServerHandler) CreateFollowGroup(ctx context.Context, in *GroupRequest, out *GroupResponse) error {
+	return h.SocialServerHandler.CreateFollowGroup(ctx, in, out)
+}
+
+func (h *socialServerHandler) RenameFollowGroup(ctx context.Context, in *GroupRequest, out *EmptyResponse) error {
+	return h.SocialServerHandler.RenameFollowGroup(ctx, in, out)
+}
+
+func (h *socialServerHandler) DeleteFollowGroup(ctx context.Context, in *GroupRequest, out *EmptyResponse) error {
+	return h.SocialServerHandler.DeleteFollowGroup(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetFollowGroups(ctx context.Context, in *GroupListRequest, out *GroupListResponse) error {
+	return h.SocialServerHandler.GetFollowGroups(ctx, in, out)
+}
+
+func (h *socialServerHandler) AddFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, out *EmptyResponse) error {
+	return h.SocialServerHandler.AddFollowGroupMembers(ctx, in, out)
+}
+
+func (h *socialServerHandler) RemoveFollowGroupMembers(ctx context.Context, in *GroupMembersRequest, out *EmptyResponse) error {
+	return h.SocialServerHandler.RemoveFollowGroupMembers(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetFollowGroupMembers(ctx context.Context, in *GroupMemberListRequest, out *ListResponse) error {
+	return h.SocialServerHandler.GetFollowGroupMembers(ctx, in, out)
+}
+
+func (h *socialServerHandler) UpdateFollowSettings(ctx context.Context, in *FollowRequest, out *EmptyResponse) error {
+	return h.SocialServerHandler.UpdateFollowSettings(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetNotifyFollowers(ctx context.Context, in *NotifyFollowersRequest, out *NotifyFollowersResponse) error {
+	return h.SocialServerHandler.GetNotifyFollowers(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetFollowStats(ctx context.Context, in *FollowStatsRequest, out *FollowStatsResponse) error {
+	return h.SocialServerHandler.GetFollowStats(ctx, in, out)
+}
+
+func (h *socialServerHandler) GetFollowerHistory(ctx context.Context, in *FollowerHistoryRequest, out *FollowerHistoryResponse) error {
+	return h.SocialServerHandler.GetFollowerHistory(ctx, in, out)
+}
+
+func (h *socialServerHandler) RestoreFollows(ctx context.Context, in *RestoreRequest, out *RestoreResponse) error {
+	return h.SocialServerHandler.RestoreFollows(ctx, in, out)
+}
+
+func (h *socialServerHandler) DeleteUserGraph(ctx context.Context, in *GraphDeletionRequest, out *GraphDeletionResponse) error {
+	return h.SocialServerHandler.DeleteUserGraph(ctx, in, out)
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"socialservice/util/constant"
+	"sort"
+)
+
+// Edge is one stored relation: uid follows TargetID, a user or a topic.
+type Edge struct {
+	UID      int64 `json:"uid"`
+	TargetID int64 `json:"target_id"`
+	Ctime    int64 `json:"ctime"`
+}
+
+type Counts struct {
+	FollowCount      int64 `json:"follow_count"`
+	FollowerCount    int64 `json:"follower_count"`
+	FollowTopicCount int64 `json:"follow_topic_count"`
+}
+
+// CountReport puts the counters in MySQL next to what the service serves,
+// so drift between them shows up.
+type CountReport struct {
+	Stored Counts `json:"stored"`
+	Served Counts `json:"served"`
+}
+
+type Graph struct {
+	UID       int64  `json:"uid"`
+	Follows   []Edge `json:"follows"`
+	Followers []Edge `json:"followers"`
+	Topics    []Edge `json:"topics"`
+}
+
+func toEdges(uid int64, ids []int64, ctimes map[int64]int64) []Edge {
+	edges := make([]Edge, 0, len(ids))
+	for _, id := range ids {
+		edges = append(edges, Edge{UID: uid, TargetID: id, Ctime: ctimes[id]})
+	}
+	return edges
+}
+
+func AdminFollows(ctx context.Context, uid int64) ([]Edge, error) {
+	ids, ctimes, err := dbGetFollow(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return toEdges(uid, ids, ctimes), nil
+}
+
+func AdminFollowers(ctx context.Context, uid int64) ([]Edge, error) {
+	ids, ctimes, err := dbGetFollower(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return toEdges(uid, ids, ctimes), nil
+}
+
+func AdminTopics(ctx context.Context, uid int64) ([]Edge, error) {
+	ids, ctimes, err := dbGetFollowTopic(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return toEdges(uid, ids, ctimes), nil
+}
+
+func AdminCounts(ctx context.Context, uid int64) (*CountReport, error) {
+	var (
+		report CountReport
+		err    error
+	)
+	report.Stored.FollowCount, report.Stored.FollowerCount, err = dbGetFollowCount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	report.Stored.FollowTopicCount, err = dbGetFollowTopicCount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	report.Served.FollowCount, report.Served.FollowerCount, err = getFollowCount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	report.Served.FollowTopicCount, err = getFollowTopicCount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// AdminFollow and AdminUnfollow go through the same path as the RPCs, so
+// MySQL, Redis and memcached stay consistent.
+func AdminFollow(ctx context.Context, uid, toUID int64) error {
+	return follow(ctx, uid, toUID, FollowSettings{Notify: constant.NotifyAll}, FollowSource{Source: FollowSourceAdmin})
+}
+
+func AdminUnfollow(ctx context.Context, uid, toUID int64) error {
+	return unfollow(ctx, uid, toUID)
+}
+
+// RebuildCache drops every cached key of uid and refills them from MySQL
+// right away instead of waiting for reads to miss.
+func RebuildCache(ctx context.Context, uid int64) error {
+	err := cacheDrop(ctx, uid)
+	if err != nil {
+		return err
+	}
+	lists := []struct {
+		key  string
+		load func(context.Context, int64) ([]int64, map[int64]int64, error)
+	}{
+		{fmt.Sprintf(RedisKeyZFollow, uid), dbGetFollow},
+		{fmt.Sprintf(RedisKeyZFollower, uid), dbGetFollower},
+		{fmt.Sprintf(RedisKeyZFollowTopic, uid), dbGetFollowTopic},
+	}
+	for _, l := range lists {
+		ids, ctimes, err := l.load(ctx, uid)
+		if err != nil {
+			return err
+		}
+		cacheSetFollow(ctx, l.key, ids, ctimes)
+	}
+	followCnt, followerCnt, err := dbGetFollowCount(ctx, uid)
+	if err != nil {
+		return err
+	}
+	topicCnt, err := dbGetFollowTopicCount(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if countCache == CountCacheMemcache {
+		mcSetFollowCount(ctx, uid, followCnt, followerCnt)
+		mcSetFollowTopicCount(ctx, uid, topicCnt)
+	} else {
+		cacheSetFollowCount(ctx, uid, followCnt, followerCnt)
+		cacheSetFollowTopicCount(ctx, uid, topicCnt)
+	}
+	return nil
+}
+
+// Recount recomputes the stored counters of uid from its relations and
+// drops the cached ones.
+func Recount(ctx context.Context, uid int64) (Counts, error) {
+	cnt, err := dbRecount(ctx, uid)
+	if err != nil {
+		return Counts{}, err
+	}
+	return cnt, cacheDropCounts(ctx, uid)
+}
+
+// ExportGraph returns every relation of uid, oldest first.
+func ExportGraph(ctx context.Context, uid int64) (*Graph, error) {
+	g := &Graph{UID: uid}
+	var err error
+	if g.Follows, err = AdminFollows(ctx, uid); err != nil {
+		return nil, err
+	}
+	if g.Followers, err = AdminFollowers(ctx, uid); err != nil {
+		return nil, err
+	}
+	if g.Topics, err = AdminTopics(ctx, uid); err != nil {
+		return nil, err
+	}
+	for _, edges := range [][]Edge{g.Follows, g.Followers, g.Topics} {
+		sort.SliceStable(edges, func(i, j int) bool {
+			return edges[i].Ctime < edges[j].Ctime
+		})
+	}
+	return g, nil
+}
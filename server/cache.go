@@ -2,48 +2,111 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/go-redis/redis/v8"
-	"socialservice/global"
+	"go.uber.org/zap"
 	"socialservice/util/cast"
-	"socialservice/util/constant"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
 	"time"
 )
 
 const (
-	SleepTime     = 500 * time.Millisecond
-	DefaultOffset = 10
-
-	RedisKeyFollowCountTTL = 5 * time.Minute
-	RedisKeyFollowListTTL  = 30 * time.Minute
-
-	RedisKeyFollowCount      = "social_service_follow_count_%v"       // uid
-	RedisKeyFollowerCount    = "social_service_follower_count_%v"     // uid
-	RedisKeyFollowTopicCount = "social_service_follow_topic_count_%v" //uid
-	RedisKeyZFollow          = "social_service_follow_%v"             // uid follow_uid ctime
-	RedisKeyZFollower        = "social_service_follower_%v"           // uid follower_uid ctime
-	RedisKeyZFollowTopic     = "social_service_follow_topic_%v"       // uid topic_id
+	// keys are hash tagged on uid so a user's sets and counters share a
+	// cluster slot and can be updated together from one script
+	RedisKeyFollowCount      = "social_service_follow_count_{%v}"       // uid
+	RedisKeyFollowerCount    = "social_service_follower_count_{%v}"     // uid
+	RedisKeyFollowTopicCount = "social_service_follow_topic_count_{%v}" //uid
+	RedisKeyZFollow          = "social_service_follow_{%v}"             // uid follow_uid ctime
+	RedisKeyZFollower        = "social_service_follower_{%v}"           // uid follower_uid ctime
+	RedisKeyZFollowTopic     = "social_service_follow_topic_{%v}"       // uid topic_id
+	RedisKeyRecentWrite      = "social_service_recent_write_{%v}"       // uid
+	RedisKeyZSuggestion      = "social_service_suggestion_{%v}"         // uid candidate_uid mutual
+	RedisKeyFollowGroups     = "social_service_follow_groups_{%v}"      // uid, JSON list of groups
+	RedisKeyZGroupMember     = "social_service_group_member_{%v}_%v"    // uid group_id member_uid ctime
 )
 
+const (
+	RedisKeyFollowerHistory = "social_service_follower_history_{%v}_%v_%v_%v_%v" // uid granularity from to version, JSON list of points
+	RedisKeyCommon          = "social_service_common_{%v}_%v_%v"                 // viewer target limit, JSON commonResult
+	// CommonTTL is how long a common followers answer is reused. Follows
+	// made in between show up once it expires.
+	CommonTTL = time.Minute
+	// RedisKeyHistoryVersion is bumped by every history snapshot, which
+	// retires the histories cached before it.
+	RedisKeyHistoryVersion = "social_service_history_version"
+	// RedisKeyHistorySnapshot marks a day whose snapshot an instance has
+	// taken on, so only one of them records it. It belongs to no user.
+	RedisKeyHistorySnapshot = "social_service_history_snapshot_%v" // day
+	// RedisKeyGraphDeletion marks a uid whose graph an instance is erasing.
+	// It is left untagged so erasing the user's keys keeps it.
+	RedisKeyGraphDeletion = "social_service_graph_deletion_%v" // uid
+)
+
+var (
+	// KEYS[1] sorted set, KEYS[2] optional counter; ARGV[1] member, ARGV[2] score.
+	// Only touches keys that are already cached, so an expired set is never
+	// recreated with a single member and a missing counter never starts at 1.
+	scriptAddRelation = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('ZADD', KEYS[1], ARGV[2], ARGV[1])
+end
+if KEYS[2] and redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('INCR', KEYS[2])
+end
+return 1
+`)
+	// KEYS[1] sorted set; ARGV score, member pairs. Like scriptAddRelation
+	// it leaves a set that is not cached alone.
+	scriptAddMembers = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('ZADD', KEYS[1], unpack(ARGV))
+end
+return 1
+`)
+	// KEYS[1] sorted set, KEYS[2] optional counter; ARGV[1] member.
+	scriptRemRelation = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+if KEYS[2] then
+	local n = redis.call('GET', KEYS[2])
+	if n and tonumber(n) > 0 then
+		redis.call('DECR', KEYS[2])
+	end
+end
+return 1
+`)
+)
+
+func runRelationScript(ctx context.Context, script *redis.Script, key, cKey string, args ...interface{}) error {
+	keys := []string{key}
+	if countCache == CountCacheRedis {
+		keys = append(keys, cKey)
+	}
+	return script.Run(ctx, redisCli, keys, args...).Err()
+}
+
 func cacheFollow(ctx context.Context, uid, toUID int64) error {
 	key := fmt.Sprintf(RedisKeyZFollow, uid)
 	fKey := fmt.Sprintf(RedisKeyZFollower, toUID)
 	cKey := fmt.Sprintf(RedisKeyFollowCount, uid)
 	cfKey := fmt.Sprintf(RedisKeyFollowerCount, toUID)
-	now := float64(time.Now().Unix())
-	if redisCli.Exists(ctx, key).Val() == 1 {
-		redisCli.ZAdd(ctx, key, &redis.Z{Member: toUID, Score: now})
+	now := time.Now().Unix()
+	err := runRelationScript(ctx, scriptAddRelation, key, cKey, toUID, now)
+	if err != nil {
+		logger.Error(ctx, "follow script", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		metrics.RedisFailure("follow")
 	}
-	if redisCli.Exists(ctx, fKey).Val() == 1 {
-		redisCli.ZAdd(ctx, fKey, &redis.Z{Member: uid, Score: now})
+	fErr := runRelationScript(ctx, scriptAddRelation, fKey, cfKey, uid, now)
+	if fErr != nil {
+		logger.Error(ctx, "follower script", logger.UID(toUID), logger.TargetID(uid), logger.Err(fErr))
+		metrics.RedisFailure("follower")
+		err = fErr
 	}
-	pipe := redisCli.Pipeline()
-	pipe.Incr(ctx, cKey)
-	pipe.Incr(ctx, cfKey)
-	_, err := pipe.Exec(ctx)
-	if err != nil {
-		global.ExcLog.Printf("ctx %v follow pipeline uid %v to_uid %v err %v", ctx, uid, toUID, err)
+	if countCache == CountCacheMemcache {
+		mcFollow(ctx, uid, toUID)
 	}
+	localInvalidate(ctx, key, fKey, localCountKey(uid), localCountKey(toUID))
 	return err
 }
 
@@ -52,55 +115,78 @@ func cacheUnfollow(ctx context.Context, uid, toUID int64) error {
 	fKey := fmt.Sprintf(RedisKeyZFollower, toUID)
 	cKey := fmt.Sprintf(RedisKeyFollowCount, uid)
 	cfKey := fmt.Sprintf(RedisKeyFollowerCount, toUID)
-	pipe := redisCli.Pipeline()
-	pipe.ZRem(ctx, key, toUID)
-	pipe.ZRem(ctx, fKey, uid)
-	pipe.Decr(ctx, cKey)
-	pipe.Decr(ctx, cfKey)
-	_, err := pipe.Exec(ctx)
+	err := runRelationScript(ctx, scriptRemRelation, key, cKey, toUID)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v unfollow pipeline uid %v to_uid %v err %v", ctx, uid, toUID, err)
+		logger.Error(ctx, "unfollow script", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		metrics.RedisFailure("unfollow")
+	}
+	fErr := runRelationScript(ctx, scriptRemRelation, fKey, cfKey, uid)
+	if fErr != nil {
+		logger.Error(ctx, "unfollower script", logger.UID(toUID), logger.TargetID(uid), logger.Err(fErr))
+		metrics.RedisFailure("unfollower")
+		err = fErr
 	}
+	if countCache == CountCacheMemcache {
+		mcUnfollow(ctx, uid, toUID)
+	}
+	localInvalidate(ctx, key, fKey, localCountKey(uid), localCountKey(toUID))
 	return err
 }
 
 func cacheFollowTopic(ctx context.Context, uid, topicID int64) error {
 	key := fmt.Sprintf(RedisKeyZFollowTopic, uid)
 	cKey := fmt.Sprintf(RedisKeyFollowTopicCount, uid)
-	if redisCli.Exists(ctx, key).Val() == 1 {
-		redisCli.ZAdd(ctx, key, &redis.Z{Member: topicID, Score: float64(time.Now().Unix())})
-	}
-	err := redisCli.Incr(ctx, cKey).Err()
+	err := runRelationScript(ctx, scriptAddRelation, key, cKey, topicID, time.Now().Unix())
 	if err != nil {
-		global.ExcLog.Printf("ctx %v cacheFollowTopic uid %v topic_id %v err %v", ctx, uid, topicID, err)
+		logger.Error(ctx, "cacheFollowTopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+		metrics.RedisFailure("follow_topic")
 	}
+	if countCache == CountCacheMemcache {
+		mcFollowTopic(ctx, uid, 1)
+	}
+	localInvalidate(ctx, key)
 	return err
 }
 
 func cacheUnfollowTopic(ctx context.Context, uid, topicID int64) error {
 	key := fmt.Sprintf(RedisKeyZFollowTopic, uid)
 	cKey := fmt.Sprintf(RedisKeyFollowTopicCount, uid)
-	pipe := redisCli.Pipeline()
-	pipe.ZRem(ctx, key, topicID)
-	pipe.Decr(ctx, cKey)
-	_, err := pipe.Exec(ctx)
+	err := runRelationScript(ctx, scriptRemRelation, key, cKey, topicID)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v cacheUnfollowTopic uid %v topic_id %v err %v", ctx, uid, topicID, err)
+		logger.Error(ctx, "cacheUnfollowTopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+		metrics.RedisFailure("unfollow_topic")
+	}
+	if countCache == CountCacheMemcache {
+		mcFollowTopic(ctx, uid, -1)
 	}
+	localInvalidate(ctx, key)
 	return err
 }
 
 func cacheGetFollowCount(ctx context.Context, uid int64) (int64, int64, error) {
 	key := fmt.Sprintf(RedisKeyFollowCount, uid)
 	fKey := fmt.Sprintf(RedisKeyFollowerCount, uid)
+	if followCount, followerCount, ok := localGetFollowCount(uid); ok {
+		metrics.CacheHit("local_follow_count")
+		return followCount, followerCount, nil
+	}
+	metrics.CacheMiss("local_follow_count")
 	val, err := redisCli.MGet(ctx, key, fKey).Result()
 	if err != nil || len(val) != 2 {
-		global.ExcLog.Printf("ctx %v get follow count uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "get follow count", logger.UID(uid), logger.Err(err))
 		return 0, 0, err
 	}
+	followVal, ok := val[0].(string)
+	followerVal, fOK := val[1].(string)
+	if !ok || !fOK {
+		metrics.CacheMiss("follow_count")
+		return 0, 0, redis.Nil
+	}
+	metrics.CacheHit("follow_count")
 
-	followCount := cast.ParseInt(val[0].(string), 0)
-	followerCount := cast.ParseInt(val[1].(string), 0)
+	followCount := cast.ParseInt(followVal, 0)
+	followerCount := cast.ParseInt(followerVal, 0)
+	localSetFollowCount(uid, followCount, followerCount)
 	return followCount, followerCount, nil
 }
 
@@ -109,82 +195,637 @@ func cacheSetFollowCount(ctx context.Context, uid, followCount, followerCount in
 	fKey := fmt.Sprintf(RedisKeyFollowerCount, uid)
 	err := redisCli.MSet(ctx, key, followCount, fKey, followerCount).Err()
 	if err != nil {
-		global.ExcLog.Printf("ctx %v set follow count uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "set follow count", logger.UID(uid), logger.Err(err))
 	}
-	redisCli.Expire(ctx, key, RedisKeyFollowCountTTL)
-	redisCli.Expire(ctx, fKey, RedisKeyFollowCountTTL)
+	redisCli.Expire(ctx, key, settings().followCountTTL)
+	redisCli.Expire(ctx, fKey, settings().followCountTTL)
 }
 
 func cacheGetFollowTopicCount(ctx context.Context, uid int64) (int64, error) {
 	key := fmt.Sprintf(RedisKeyFollowTopicCount, uid)
 	val, err := redisCli.Get(ctx, key).Result()
-	if err != nil && err != redis.Nil {
-		global.ExcLog.Printf("ctx %v cacheGetFollowTopicCount uid %v err %v", ctx, uid, err)
+	if err == redis.Nil {
+		metrics.CacheMiss("follow_topic_count")
 		return 0, err
 	}
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowTopicCount", logger.UID(uid), logger.Err(err))
+		return 0, err
+	}
+	metrics.CacheHit("follow_topic_count")
 	return cast.ParseInt(val, 0), nil
 }
 
 func cacheSetFollowTopicCount(ctx context.Context, uid, topicCnt int64) {
 	key := fmt.Sprintf(RedisKeyFollowTopicCount, uid)
-	err := redisCli.Set(ctx, key, topicCnt, RedisKeyFollowCountTTL).Err()
+	err := redisCli.Set(ctx, key, topicCnt, settings().followCountTTL).Err()
 	if err != nil {
-		global.ExcLog.Printf("ctx %v cacheSetFollowTopicCount uid %v topic_count %v err %v", ctx, uid, topicCnt)
+		logger.Error(ctx, "cacheSetFollowTopicCount", logger.UID(uid), zap.Int64("topic_count", topicCnt), logger.Err(err))
 	}
 }
 
 func cacheGetFollow(ctx context.Context, key string, cursor, offset int64) ([]int64, bool, error) {
-	val, err := redisCli.ZRevRange(ctx, key, cursor, cursor+offset).Result()
+	// only the first page is hot enough to be worth keeping in process
+	if cursor == 0 {
+		if uids, hasMore, ok := localGetFollow(key, offset); ok {
+			metrics.CacheHit("local_follow_list")
+			return uids, hasMore, nil
+		}
+		metrics.CacheMiss("local_follow_list")
+	}
+	pipe := redisCli.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	page := pipe.ZRevRange(ctx, key, cursor, cursor+offset)
+	_, err := pipe.Exec(ctx)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v cache get key %v cursor %v err %v", ctx, key, cursor, err)
+		logger.Error(ctx, "cache get", zap.String("key", key), zap.Int64("cursor", cursor), logger.Err(err))
 		return nil, false, err
 	}
+	// an empty set is never cached, so a missing key has to be read from
+	// MySQL, while an empty page past the end of a cached set is the end
+	if exists.Val() == 0 {
+		metrics.CacheMiss("follow_list")
+		return nil, false, redis.Nil
+	}
+	metrics.CacheHit("follow_list")
+	val := page.Val()
 	var hasMore bool
 	if int64(len(val)) > offset {
 		hasMore = true
+		val = val[:offset]
 	}
 	uids := make([]int64, 0, offset)
-	for _, v := range val[:len(val)-1] {
+	for _, v := range val {
 		uid := cast.ParseInt(v, 0)
 		uids = append(uids, uid)
 	}
+	if cursor == 0 {
+		localSetFollow(key, offset, uids, hasMore)
+	}
 	return uids, hasMore, nil
 }
 
 func cacheSetFollow(ctx context.Context, key string, uids []int64, utMap map[int64]int64) {
-	for i := 0; i < len(uids); i += constant.BatchSize {
-		z := make([]*redis.Z, 0, constant.BatchSize)
+	t := settings()
+	for i := 0; i < len(uids); i += t.batchSize {
+		z := make([]*redis.Z, 0, t.batchSize)
 		left := i
-		right := i + constant.BatchSize
+		right := i + t.batchSize
 		if right > len(uids) {
 			right = len(uids)
 		}
 		for j := left; j < right; j++ {
-			z = append(z, &redis.Z{Member: uids[i], Score: float64(utMap[uids[i]])})
+			z = append(z, &redis.Z{Member: uids[j], Score: float64(utMap[uids[j]])})
 		}
 		err := redisCli.ZAdd(ctx, key, z...).Err()
 		if err != nil {
-			global.ExcLog.Printf("ctx %v set follow z %v err %v", ctx, z, err)
+			logger.Error(ctx, "set follow", zap.Any("z", z), logger.Err(err))
 			continue
 		}
-		time.Sleep(SleepTime)
+		time.Sleep(t.batchSleep)
 	}
-	redisCli.Expire(ctx, key, RedisKeyFollowListTTL)
+	redisCli.Expire(ctx, key, t.followListTTL)
 }
 
-func getAllStream(ctx context.Context, key string, cursor uint64) ([]int64, uint64, error) {
+// cacheDrop deletes every cached relation and counter of uid, here and in
+// the other instances' local caches.
+func cacheDrop(ctx context.Context, uid int64) error {
+	keys := []string{
+		fmt.Sprintf(RedisKeyZFollow, uid),
+		fmt.Sprintf(RedisKeyZFollower, uid),
+		fmt.Sprintf(RedisKeyZFollowTopic, uid),
+		fmt.Sprintf(RedisKeyFollowCount, uid),
+		fmt.Sprintf(RedisKeyFollowerCount, uid),
+		fmt.Sprintf(RedisKeyFollowTopicCount, uid),
+		fmt.Sprintf(RedisKeyZSuggestion, uid),
+	}
+	// all keys share the {uid} hash tag, so one DEL covers them
+	err := redisCli.Del(ctx, keys...).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheDrop", logger.UID(uid), logger.Err(err))
+		metrics.RedisFailure("drop")
+		return err
+	}
+	if countCache == CountCacheMemcache {
+		mcDrop(ctx, uid)
+	}
+	localInvalidate(ctx, keys[0], keys[1], keys[2], localCountKey(uid))
+	return nil
+}
+
+// cachePurgeUser deletes every key tagged with uid, whatever it caches,
+// and what cacheDrop clears besides Redis. The tag puts the keys in one
+// slot, so scanning the node holding it finds them all. each runs after
+// every batch of the scan.
+func cachePurgeUser(ctx context.Context, uid int64, each func(context.Context) error) error {
+	err := cacheDrop(ctx, uid)
+	if err != nil {
+		return err
+	}
+	node, err := redisCluster.MasterForKey(ctx, fmt.Sprintf(RedisKeyZFollow, uid))
+	if err != nil {
+		logger.Error(ctx, "cachePurgeUser node", logger.UID(uid), logger.Err(err))
+		return err
+	}
+	match := fmt.Sprintf("social_service_*{%v}*", uid)
+	var cursor uint64
+	for {
+		var keys []string
+		keys, cursor, err = node.Scan(ctx, cursor, match, int64(settings().batchSize)).Result()
+		if err == nil && len(keys) > 0 {
+			err = node.Del(ctx, keys...).Err()
+		}
+		if err != nil {
+			logger.Error(ctx, "cachePurgeUser", logger.UID(uid), logger.Err(err))
+			metrics.RedisFailure("purge")
+			return err
+		}
+		if cursor == 0 {
+			return nil
+		}
+		err = each(ctx)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// cacheDropCounts deletes the cached counters of uid.
+func cacheDropCounts(ctx context.Context, uid int64) error {
+	err := redisCli.Del(ctx,
+		fmt.Sprintf(RedisKeyFollowCount, uid),
+		fmt.Sprintf(RedisKeyFollowerCount, uid),
+		fmt.Sprintf(RedisKeyFollowTopicCount, uid),
+	).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheDropCounts", logger.UID(uid), logger.Err(err))
+		metrics.RedisFailure("drop_counts")
+		return err
+	}
+	if countCache == CountCacheMemcache {
+		mcDrop(ctx, uid)
+	}
+	localInvalidate(ctx, localCountKey(uid))
+	return nil
+}
+
+// cacheMarkWrite flags uids as having just had their relations mutated,
+// so their reads go to the master and skip cache backfill until replicas
+// catch up. A write changes both sides of an edge, so callers pass both.
+func cacheMarkWrite(ctx context.Context, uids ...int64) {
+	ttl := settings().recentWriteTTL
+	pipe := redisCli.Pipeline()
+	for _, uid := range uids {
+		pipe.Set(ctx, fmt.Sprintf(RedisKeyRecentWrite, uid), 1, ttl)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheMarkWrite", zap.Int64s("uids", uids), logger.Err(err))
+	}
+}
+
+func cacheIsRecentWrite(ctx context.Context, uid int64) bool {
+	key := fmt.Sprintf(RedisKeyRecentWrite, uid)
+	n, err := redisCli.Exists(ctx, key).Result()
+	if err != nil {
+		logger.Error(ctx, "cacheIsRecentWrite", logger.UID(uid), logger.Err(err))
+		return false
+	}
+	return n == 1
+}
+
+// cacheGetFollowHead reads the newest limit follows of every uid in one
+// pipeline. A uid whose set is not cached is left out of the result.
+func cacheGetFollowHead(ctx context.Context, uids []int64, limit int64) (map[int64][]int64, error) {
+	pipe := redisCli.Pipeline()
+	exists := make([]*redis.IntCmd, len(uids))
+	heads := make([]*redis.StringSliceCmd, len(uids))
+	for i, uid := range uids {
+		key := fmt.Sprintf(RedisKeyZFollow, uid)
+		exists[i] = pipe.Exists(ctx, key)
+		heads[i] = pipe.ZRevRange(ctx, key, 0, limit-1)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowHead", zap.Int("users", len(uids)), logger.Err(err))
+		metrics.RedisFailure("follow_head")
+		return nil, err
+	}
+	res := make(map[int64][]int64, len(uids))
+	for i, uid := range uids {
+		if exists[i].Val() == 0 {
+			metrics.CacheMiss("follow_list")
+			continue
+		}
+		metrics.CacheHit("follow_list")
+		ids := make([]int64, 0, len(heads[i].Val()))
+		for _, v := range heads[i].Val() {
+			ids = append(ids, cast.ParseInt(v, 0))
+		}
+		res[uid] = ids
+	}
+	return res, nil
+}
+
+// cacheGetFollowing reports which of ids uid follows. ok is false when the
+// follow set of uid is not cached and the answer has to come from MySQL.
+func cacheGetFollowing(ctx context.Context, uid int64, ids []int64) (map[int64]bool, bool, error) {
+	key := fmt.Sprintf(RedisKeyZFollow, uid)
+	pipe := redisCli.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	scores := make([]*redis.FloatCmd, len(ids))
+	for i, id := range ids {
+		scores[i] = pipe.ZScore(ctx, key, cast.FormatInt(id))
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil && err != redis.Nil {
+		logger.Error(ctx, "cacheGetFollowing", logger.UID(uid), logger.Err(err))
+		metrics.RedisFailure("following")
+		return nil, false, err
+	}
+	if exists.Val() == 0 {
+		return nil, false, nil
+	}
+	following := make(map[int64]bool, len(ids))
+	for i, id := range ids {
+		if scores[i].Err() == nil {
+			following[id] = true
+		}
+	}
+	return following, true, nil
+}
+
+// cacheGetCommon intersects the follow set of viewer with the follower set
+// of target. The two live in different cluster slots, so rather than
+// ZINTERSTORE the smaller set is read newest first in batches and each
+// batch looked up in the other with pipelined ZSCOREs. uids holds the
+// first limit members found, total counts them all. ok is false when
+// either set is not cached.
+func cacheGetCommon(ctx context.Context, viewer, target, limit int64) ([]int64, int64, bool, error) {
+	small, big := fmt.Sprintf(RedisKeyZFollow, viewer), fmt.Sprintf(RedisKeyZFollower, target)
+	pipe := redisCli.Pipeline()
+	smallCard, bigCard := pipe.ZCard(ctx, small), pipe.ZCard(ctx, big)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheGetCommon card", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
+		metrics.RedisFailure("common")
+		return nil, 0, false, err
+	}
+	// an empty set is never cached, so zero means missing
+	if smallCard.Val() == 0 || bigCard.Val() == 0 {
+		metrics.CacheMiss("common")
+		return nil, 0, false, nil
+	}
+	metrics.CacheHit("common")
+	n := smallCard.Val()
+	if bigCard.Val() < n {
+		small, big, n = big, small, bigCard.Val()
+	}
+
+	batch := int64(settings().batchSize)
 	var (
-		vals []string
-		err  error
+		uids  []int64
+		total int64
 	)
-	vals, cursor, err = redisCli.ZScan(ctx, key, cursor, "", constant.BatchSize).Result()
+	for start := int64(0); start < n; start += batch {
+		members, err := redisCli.ZRevRange(ctx, small, start, start+batch-1).Result()
+		if err != nil {
+			logger.Error(ctx, "cacheGetCommon range", zap.String("key", small), zap.Int64("start", start), logger.Err(err))
+			metrics.RedisFailure("common")
+			return nil, 0, false, err
+		}
+		pipe := redisCli.Pipeline()
+		scores := make([]*redis.FloatCmd, len(members))
+		for i, m := range members {
+			scores[i] = pipe.ZScore(ctx, big, m)
+		}
+		_, err = pipe.Exec(ctx)
+		if err != nil && err != redis.Nil {
+			logger.Error(ctx, "cacheGetCommon score", zap.String("key", big), logger.Err(err))
+			metrics.RedisFailure("common")
+			return nil, 0, false, err
+		}
+		for i, m := range members {
+			if scores[i].Err() != nil {
+				continue
+			}
+			total++
+			if int64(len(uids)) < limit {
+				uids = append(uids, cast.ParseInt(m, 0))
+			}
+		}
+	}
+	return uids, total, true, nil
+}
+
+// commonResult is a getCommonFollowers answer as cached under
+// RedisKeyCommon.
+type commonResult struct {
+	UIDs  []int64 `json:"uids"`
+	Total int64   `json:"total"`
+}
+
+func cacheGetCommonResult(ctx context.Context, viewer, target, limit int64) ([]int64, int64, bool) {
+	key := fmt.Sprintf(RedisKeyCommon, viewer, target, limit)
+	val, err := redisCli.Get(ctx, key).Bytes()
 	if err != nil {
-		global.ExcLog.Printf("ctx %v getAllStream key %v cursor %v err %v", ctx, key, cursor, err)
-		return nil, 0, err
+		if err != redis.Nil {
+			logger.Error(ctx, "cacheGetCommonResult", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
+		}
+		metrics.CacheMiss("common_result")
+		return nil, 0, false
+	}
+	var res commonResult
+	err = json.Unmarshal(val, &res)
+	if err != nil {
+		logger.Error(ctx, "cacheGetCommonResult decode", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
+		return nil, 0, false
+	}
+	metrics.CacheHit("common_result")
+	return res.UIDs, res.Total, true
+}
+
+func cacheSetCommonResult(ctx context.Context, viewer, target, limit int64, uids []int64, total int64) {
+	key := fmt.Sprintf(RedisKeyCommon, viewer, target, limit)
+	val, err := json.Marshal(commonResult{UIDs: uids, Total: total})
+	if err == nil {
+		err = redisCli.Set(ctx, key, val, CommonTTL).Err()
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheSetCommonResult", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
 	}
-	uids := make([]int64, 0, len(vals))
-	for _, v := range vals {
+}
+
+// cacheGetSuggestions returns the cached top limit suggestions of uid. ok
+// is false when none are cached; an empty list is cached as a lone 0.
+func cacheGetSuggestions(ctx context.Context, uid, limit int64) ([]suggestion, bool, error) {
+	key := fmt.Sprintf(RedisKeyZSuggestion, uid)
+	val, err := redisCli.ZRevRangeWithScores(ctx, key, 0, limit-1).Result()
+	if err != nil {
+		logger.Error(ctx, "cacheGetSuggestions", logger.UID(uid), logger.Err(err))
+		return nil, false, err
+	}
+	if len(val) == 0 {
+		metrics.CacheMiss("suggestion")
+		return nil, false, nil
+	}
+	metrics.CacheHit("suggestion")
+	res := make([]suggestion, 0, len(val))
+	for _, z := range val {
+		id := cast.ParseInt(z.Member.(string), 0)
+		if id == 0 {
+			continue
+		}
+		res = append(res, suggestion{uid: id, mutual: int64(z.Score)})
+	}
+	return res, true, nil
+}
+
+func cacheSetSuggestions(ctx context.Context, uid int64, list []suggestion) {
+	key := fmt.Sprintf(RedisKeyZSuggestion, uid)
+	z := make([]*redis.Z, 0, len(list)+1)
+	for _, s := range list {
+		z = append(z, &redis.Z{Member: s.uid, Score: float64(s.mutual)})
+	}
+	if len(z) == 0 {
+		z = append(z, &redis.Z{Member: 0})
+	}
+	pipe := redisCli.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, z...)
+	pipe.Expire(ctx, key, settings().suggestTTL)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheSetSuggestions", logger.UID(uid), logger.Err(err))
+		metrics.RedisFailure("set_suggestion")
+	}
+}
+
+func cacheDropSuggestions(ctx context.Context, uid int64) {
+	err := redisCli.Del(ctx, fmt.Sprintf(RedisKeyZSuggestion, uid)).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheDropSuggestions", logger.UID(uid), logger.Err(err))
+	}
+}
+
+func cacheGetFollowGroups(ctx context.Context, uid int64) ([]FollowGroup, bool, error) {
+	val, err := redisCli.Get(ctx, fmt.Sprintf(RedisKeyFollowGroups, uid)).Bytes()
+	if err == redis.Nil {
+		metrics.CacheMiss("follow_groups")
+		return nil, false, nil
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowGroups", logger.UID(uid), logger.Err(err))
+		return nil, false, err
+	}
+	var groups []FollowGroup
+	err = json.Unmarshal(val, &groups)
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowGroups decode", logger.UID(uid), logger.Err(err))
+		return nil, false, err
+	}
+	metrics.CacheHit("follow_groups")
+	return groups, true, nil
+}
+
+func cacheSetFollowGroups(ctx context.Context, uid int64, groups []FollowGroup) {
+	val, err := json.Marshal(groups)
+	if err == nil {
+		err = redisCli.Set(ctx, fmt.Sprintf(RedisKeyFollowGroups, uid), val, settings().followListTTL).Err()
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheSetFollowGroups", logger.UID(uid), logger.Err(err))
+	}
+}
+
+// cacheDropFollowGroups drops the cached groups of uid and the members of
+// groupIDs.
+func cacheDropFollowGroups(ctx context.Context, uid int64, groupIDs ...int64) {
+	keys := []string{fmt.Sprintf(RedisKeyFollowGroups, uid)}
+	for _, id := range groupIDs {
+		keys = append(keys, fmt.Sprintf(RedisKeyZGroupMember, uid, id))
+	}
+	err := redisCli.Del(ctx, keys...).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheDropFollowGroups", logger.UID(uid), logger.Err(err))
+		metrics.RedisFailure("drop_groups")
+	}
+}
+
+// cacheGetGroupMembers reads a page of members, newest first. ok is false
+// when the group is not cached; an empty group is cached as a lone 0.
+func cacheGetGroupMembers(ctx context.Context, uid, groupID, cursor, offset int64) ([]int64, bool, bool, error) {
+	key := fmt.Sprintf(RedisKeyZGroupMember, uid, groupID)
+	pipe := redisCli.Pipeline()
+	exists := pipe.Exists(ctx, key)
+	page := pipe.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{Min: "1", Max: "+inf", Offset: cursor, Count: offset + 1})
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheGetGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		return nil, false, false, err
+	}
+	if exists.Val() == 0 {
+		metrics.CacheMiss("group_members")
+		return nil, false, false, nil
+	}
+	metrics.CacheHit("group_members")
+	val := page.Val()
+	var hasMore bool
+	if int64(len(val)) > offset {
+		hasMore = true
+		val = val[:offset]
+	}
+	uids := make([]int64, 0, len(val))
+	for _, v := range val {
 		uids = append(uids, cast.ParseInt(v, 0))
 	}
-	return uids, cursor, nil
+	return uids, hasMore, true, nil
+}
+
+func cacheSetGroupMembers(ctx context.Context, uid, groupID int64, members []int64, ctimes map[int64]int64) {
+	key := fmt.Sprintf(RedisKeyZGroupMember, uid, groupID)
+	z := make([]*redis.Z, 0, len(members)+1)
+	// the placeholder scores 0 and is skipped by reads, which start at 1
+	z = append(z, &redis.Z{Member: 0})
+	for _, m := range members {
+		z = append(z, &redis.Z{Member: m, Score: float64(ctimes[m])})
+	}
+	pipe := redisCli.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, z...)
+	pipe.Expire(ctx, key, settings().followListTTL)
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheSetGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		metrics.RedisFailure("set_group_members")
+	}
+}
+
+func cacheAddGroupMembers(ctx context.Context, uid, groupID int64, members []int64, now int64) error {
+	key := fmt.Sprintf(RedisKeyZGroupMember, uid, groupID)
+	args := make([]interface{}, 0, 2*len(members))
+	for _, m := range members {
+		args = append(args, now, m)
+	}
+	err := scriptAddMembers.Run(ctx, redisCli, []string{key}, args...).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheAddGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		metrics.RedisFailure("add_group_members")
+	}
+	return err
+}
+
+func cacheRemGroupMembers(ctx context.Context, uid, groupID int64, members []int64) error {
+	key := fmt.Sprintf(RedisKeyZGroupMember, uid, groupID)
+	vals := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		vals = append(vals, m)
+	}
+	err := redisCli.ZRem(ctx, key, vals...).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheRemGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		metrics.RedisFailure("rem_group_members")
+	}
+	return err
+}
+
+// cacheRemFromGroups takes member out of the cached groups of uid after an
+// unfollow. The keys share the {uid} tag, so one pipeline covers them.
+func cacheRemFromGroups(ctx context.Context, uid, member int64, groupIDs []int64) {
+	if len(groupIDs) == 0 {
+		return
+	}
+	pipe := redisCli.Pipeline()
+	for _, id := range groupIDs {
+		pipe.ZRem(ctx, fmt.Sprintf(RedisKeyZGroupMember, uid, id), member)
+	}
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error(ctx, "cacheRemFromGroups", logger.UID(uid), logger.TargetID(member), logger.Err(err))
+		metrics.RedisFailure("rem_from_groups")
+	}
+}
+
+// cacheGetHistoryVersion returns how many history snapshots were taken.
+func cacheGetHistoryVersion(ctx context.Context) (int64, error) {
+	version, err := redisCli.Get(ctx, RedisKeyHistoryVersion).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheGetHistoryVersion", logger.Err(err))
+		metrics.RedisFailure("history_version")
+	}
+	return version, err
+}
+
+func cacheBumpHistoryVersion(ctx context.Context) {
+	err := redisCli.Incr(ctx, RedisKeyHistoryVersion).Err()
+	if err != nil {
+		logger.Error(ctx, "cacheBumpHistoryVersion", logger.Err(err))
+		metrics.RedisFailure("history_version")
+	}
+}
+
+// cacheGetFollowerHistory returns the stored points of uid cached for the
+// periods from to to at version. A snapshot bumps the version, so entries
+// read before it are never served again and are left to expire.
+func cacheGetFollowerHistory(ctx context.Context, uid int64, granularity string, from, to time.Time, version int64) ([]FollowerHistory, bool, error) {
+	key := fmt.Sprintf(RedisKeyFollowerHistory, uid, granularity, from.Format(statDayLayout), to.Format(statDayLayout), version)
+	val, err := redisCli.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		metrics.CacheMiss("follower_history")
+		return nil, false, nil
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowerHistory", logger.UID(uid), logger.Err(err))
+		return nil, false, err
+	}
+	var points []FollowerHistory
+	err = json.Unmarshal(val, &points)
+	if err != nil {
+		logger.Error(ctx, "cacheGetFollowerHistory decode", logger.UID(uid), logger.Err(err))
+		return nil, false, err
+	}
+	metrics.CacheHit("follower_history")
+	return points, true, nil
+}
+
+func cacheSetFollowerHistory(ctx context.Context, uid int64, granularity string, from, to time.Time, version int64, points []FollowerHistory) {
+	key := fmt.Sprintf(RedisKeyFollowerHistory, uid, granularity, from.Format(statDayLayout), to.Format(statDayLayout), version)
+	val, err := json.Marshal(points)
+	if err == nil {
+		err = redisCli.Set(ctx, key, val, settings().historyTTL).Err()
+	}
+	if err != nil {
+		logger.Error(ctx, "cacheSetFollowerHistory", logger.UID(uid), logger.Err(err))
+	}
+}
+
+// getAllStream returns the members of the sorted set key from cursor on,
+// a batch at a time, and the cursor to continue from, 0 once done. A
+// missing key is redis.Nil on the first call.
+func getAllStream(ctx context.Context, key string, cursor uint64) ([]int64, uint64, error) {
+	vals, next, err := redisCli.ZScan(ctx, key, cursor, "", int64(settings().batchSize)).Result()
+	if err != nil {
+		logger.Error(ctx, "getAllStream", zap.String("key", key), zap.Uint64("cursor", cursor), logger.Err(err))
+		return nil, 0, err
+	}
+	// a missing key scans like an empty one
+	if cursor == 0 && len(vals) == 0 {
+		n, err := redisCli.Exists(ctx, key).Result()
+		if err != nil {
+			logger.Error(ctx, "getAllStream", zap.String("key", key), logger.Err(err))
+			return nil, 0, err
+		}
+		if n == 0 {
+			return nil, 0, redis.Nil
+		}
+	}
+	// ZSCAN replies with member, score pairs
+	uids := make([]int64, 0, len(vals)/2)
+	for i := 0; i < len(vals); i += 2 {
+		uids = append(uids, cast.ParseInt(vals[i], 0))
+	}
+	return uids, next, nil
 }
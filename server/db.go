@@ -3,23 +3,96 @@ package server
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
-	"socialservice/global"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.uber.org/zap"
+	"socialservice/util/constant"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
+	"socialservice/util/tracing"
 	"time"
 )
 
-func dbFollow(ctx context.Context, uid, toUID int64) error {
+const mysqlErrDupEntry = 1062
+
+// traceDB starts a span for a query function; the returned func ends it
+// and records the query latency.
+func traceDB(ctx context.Context, query string) (context.Context, func()) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, query, semconv.DBSystemMySQL)
+	return ctx, func() {
+		span.End()
+		metrics.ObserveDB(query, start)
+	}
+}
+
+// reviveOrCreate inserts row, or brings back the soft-deleted row it
+// would collide with, set to fields: a soft delete keeps the unique key.
+func reviveOrCreate(tx *gorm.DB, row interface{}, fields map[string]interface{}, query string, args ...interface{}) error {
+	fields["deleted_at"] = nil
+	res := tx.Unscoped().Model(row).Where(query, args...).Where("deleted_at IS NOT NULL").Updates(fields)
+	if res.Error != nil || res.RowsAffected > 0 {
+		return res.Error
+	}
+	return tx.Create(row).Error
+}
+
+// dbAudit records a change of an edge in the transaction making it.
+func dbAudit(tx *gorm.DB, uid, targetID int64, followType int32, action, source string, now time.Time) error {
+	return tx.Create(&FollowAudit{UID: uid, TargetID: targetID, FollowType: followType, Action: action, Source: source, Ctime: now}).Error
+}
+
+// dbRefuseErased fails the transaction changing an edge of uids when the
+// graph of one of them is being or was erased. The read locks, so a
+// deletion starting meanwhile waits for the edge and then erases it.
+func dbRefuseErased(ctx context.Context, tx *gorm.DB, uids ...int64) error {
+	var erased []int64
+	err := tx.Model(&GraphDeletion{}).Set("gorm:query_option", "LOCK IN SHARE MODE").Where("uid IN (?)", uids).Pluck("uid", &erased).Error
+	if err != nil {
+		logger.Error(ctx, "dbRefuseErased", zap.Int64s("uids", uids), logger.Err(err))
+		return err
+	}
+	if len(erased) > 0 {
+		return notFound("uid %v was deleted", erased[0])
+	}
+	return nil
+}
+
+func dbFollow(ctx context.Context, uid, toUID int64, settings FollowSettings, source FollowSource) error {
+	ctx, done := traceDB(ctx, "dbFollow")
+	defer done()
+	now := time.Now()
 	followItem := Follow{
 		UID:       uid,
 		FollowUID: toUID,
-		Ctime:     time.Now(),
-		Mtime:     time.Now(),
+		Notify:    settings.Notify,
+		Special:   settings.Special,
+		Source:    source.Source,
+		Client:    source.Client,
+		Ctime:     now,
+		Mtime:     now,
 	}
 	follower := Follower{
 		UID:         toUID,
 		FollowerUID: uid,
-		Ctime:       time.Now(),
-		Mtime:       time.Now(),
+		Notify:      settings.Notify,
+		Special:     settings.Special,
+		Source:      source.Source,
+		Client:      source.Client,
+		Ctime:       now,
+		Mtime:       now,
+	}
+	fields := func() map[string]interface{} {
+		return map[string]interface{}{
+			"notify":  settings.Notify,
+			"special": settings.Special,
+			"source":  source.Source,
+			"client":  source.Client,
+			"ctime":   now,
+			"mtime":   now,
+		}
 	}
 	followCount := FollowCount{
 		UID:           uid,
@@ -33,36 +106,57 @@ func dbFollow(ctx context.Context, uid, toUID int64) error {
 	}
 	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
 	defer tx.Rollback()
-	err := tx.Create(followItem).Error
+	err := dbRefuseErased(ctx, tx, uid, toUID)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v add user_follow uid %v to_uid %v err %v", ctx, uid, toUID, err)
 		return err
 	}
-	err = tx.Create(follower).Error
+	err = reviveOrCreate(tx, &followItem, fields(), "uid = ? and follow_uid = ?", uid, toUID)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v add user_follower uid %v to_uid %v err %v", ctx, toUID, uid, err)
+		logger.Error(ctx, "add user_follow", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
 		return err
 	}
-	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follower_count = follower_count + 1").Create(&followCount).Error
+	err = reviveOrCreate(tx, &follower, fields(), "uid = ? and follower_uid = ?", toUID, uid)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v add user_follow_count uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "add user_follower", logger.UID(toUID), logger.TargetID(uid), logger.Err(err))
+		return err
+	}
+	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follow_count = follow_count + 1").Create(&followCount).Error
+	if err != nil {
+		logger.Error(ctx, "add user_follow_count", logger.UID(uid), logger.Err(err))
 		return err
 	}
 	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follower_count = follower_count + 1").Create(&followerCount).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v add user_follower_count uid %v err %v", ctx, toUID, err)
+		logger.Error(ctx, "add user_follower_count", logger.UID(toUID), logger.Err(err))
 		return err
 	}
-	tx.Commit()
-	return nil
+	stat := FollowStatDaily{UID: toUID, Day: statDay(now), Gained: 1}
+	err = tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE gained = gained + 1").Create(&stat).Error
+	if err != nil {
+		logger.Error(ctx, "add follow_stat_daily", logger.UID(toUID), logger.Err(err))
+		return err
+	}
+	err = dbAudit(tx, uid, toUID, constant.FollowTypePerson, AuditFollow, source.Source, now)
+	if err != nil {
+		logger.Error(ctx, "add follow_audit", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbFollow commit", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+	}
+	return err
 }
 
 func dbFollowTopic(ctx context.Context, uid, topicID int64) error {
+	ctx, done := traceDB(ctx, "dbFollowTopic")
+	defer done()
+	now := time.Now()
 	followTopicItem := FollowTopic{
 		UID:     uid,
 		TopicID: topicID,
-		Ctime:   time.Now(),
-		Mtime:   time.Now(),
+		Ctime:   now,
+		Mtime:   now,
 	}
 	followTopicCount := FollowTopicCount{
 		UID:         uid,
@@ -70,21 +164,37 @@ func dbFollowTopic(ctx context.Context, uid, topicID int64) error {
 	}
 	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
 	defer tx.Rollback()
-	err := dbCli.Create(&followTopicItem).Error
+	err := dbRefuseErased(ctx, tx, uid)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v create followtopic uid %v topicid %v err %v", ctx, uid, topicID, err)
+		return err
+	}
+	err = reviveOrCreate(tx, &followTopicItem, map[string]interface{}{"ctime": now, "mtime": now}, "uid = ? and topic_id = ?", uid, topicID)
+	if err != nil {
+		logger.Error(ctx, "create followtopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
 		return err
 	}
 	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follow_count = follow_count + 1").Create(&followTopicCount).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx add followtopiccnt uid %v err %v", uid, err)
+		logger.Error(ctx, "add followtopiccnt", logger.UID(uid), logger.Err(err))
 		return err
 	}
-	tx.Commit()
-	return nil
+	err = dbAudit(tx, uid, topicID, constant.FollowTypeTopic, AuditFollow, "", now)
+	if err != nil {
+		logger.Error(ctx, "add follow_audit", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbFollowTopic commit", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+	}
+	return err
 }
 
-func dbUnfollow(ctx context.Context, uid, toUID int64) error {
+// dbUnfollow also takes toUID out of every follow group of uid and returns
+// those groups, so their cached members can be updated.
+func dbUnfollow(ctx context.Context, uid, toUID int64) ([]int64, error) {
+	ctx, done := traceDB(ctx, "dbUnfollow")
+	defer done()
 	followCount := FollowCount{
 		UID:           uid,
 		FollowCount:   1,
@@ -97,76 +207,248 @@ func dbUnfollow(ctx context.Context, uid, toUID int64) error {
 	}
 	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
 	defer tx.Rollback()
-	err := dbCli.Where("uid = ? and follow_uid = ?", uid, toUID).Delete(&Follow{}).Error
+	err := dbRefuseErased(ctx, tx, uid, toUID)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete user_follow uid %v to_uid %v err %v", ctx, uid, toUID, err)
-		return err
+		return nil, err
+	}
+	res := tx.Where("uid = ? and follow_uid = ?", uid, toUID).Delete(&Follow{})
+	if res.Error != nil {
+		logger.Error(ctx, "delete user_follow", logger.UID(uid), logger.TargetID(toUID), logger.Err(res.Error))
+		return nil, res.Error
 	}
-	err = dbCli.Where("uid = ? and follower_uid = ?", toUID, uid).Delete(&Follower{}).Error
+	if res.RowsAffected == 0 {
+		// not following, so there is nothing to count down
+		return nil, nil
+	}
+	err = tx.Where("uid = ? and follower_uid = ?", toUID, uid).Delete(&Follower{}).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete user_follower uid %v to_uid %v err %v", ctx, toUID, uid, err)
-		return err
+		logger.Error(ctx, "delete user_follower", logger.UID(toUID), logger.TargetID(uid), logger.Err(err))
+		return nil, err
 	}
-	err = dbCli.Model(&followCount).Where("uid = ? and follow_count > 0", uid).Update("follower_count", gorm.Expr("follow_count-1")).Error
+	err = tx.Model(&followCount).Where("uid = ? and follow_count > 0", uid).Update("follow_count", gorm.Expr("follow_count-1")).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete user_follow_count uid %v err %v", ctx, uid, err)
-		return err
+		logger.Error(ctx, "delete user_follow_count", logger.UID(uid), logger.Err(err))
+		return nil, err
 	}
-	err = dbCli.Model(&followerCount).Where("uid = ? and follower_count > 0", toUID).Update("follower_counter", gorm.Expr("follower_count-1")).Error
+	err = tx.Model(&followerCount).Where("uid = ? and follower_count > 0", toUID).Update("follower_count", gorm.Expr("follower_count-1")).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete user_follower_count uid %v err %v", ctx, toUID, err)
-		return err
+		logger.Error(ctx, "delete user_follower_count", logger.UID(toUID), logger.Err(err))
+		return nil, err
 	}
-	tx.Commit()
-	return nil
+	now := time.Now()
+	stat := FollowStatDaily{UID: toUID, Day: statDay(now), Lost: 1}
+	err = tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE lost = lost + 1").Create(&stat).Error
+	if err != nil {
+		logger.Error(ctx, "add follow_stat_daily", logger.UID(toUID), logger.Err(err))
+		return nil, err
+	}
+	err = dbAudit(tx, uid, toUID, constant.FollowTypePerson, AuditUnfollow, "", now)
+	if err != nil {
+		logger.Error(ctx, "add follow_audit", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return nil, err
+	}
+	var groupIDs []int64
+	err = tx.Model(&FollowGroupMember{}).Where("uid = ? and member_uid = ?", uid, toUID).Pluck("group_id", &groupIDs).Error
+	if err == nil && len(groupIDs) > 0 {
+		err = tx.Where("uid = ? and member_uid = ?", uid, toUID).Delete(&FollowGroupMember{}).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "delete follow_group_member", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return nil, err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbUnfollow commit", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return nil, err
+	}
+	return groupIDs, nil
 }
 
 func dbUnfollowTopic(ctx context.Context, uid, topicID int64) error {
+	ctx, done := traceDB(ctx, "dbUnfollowTopic")
+	defer done()
 	followTopicCount := FollowTopicCount{
 		UID:         uid,
 		FollowCount: 1,
 	}
 	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
 	defer tx.Rollback()
-	err := dbCli.Where("uid = ? and topic_id = ?", uid, topicID).Delete(&FollowTopicCount{}).Error
+	err := dbRefuseErased(ctx, tx, uid)
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete followtopic uid %v topicid %v err %v", ctx, uid, topicID, err)
 		return err
 	}
-	err = dbCli.Model(&followTopicCount).Where("uid = ? and follow_count > 0", uid).Update("follow_count", gorm.Expr("follow_count - 1")).Error
+	res := tx.Where("uid = ? and topic_id = ?", uid, topicID).Delete(&FollowTopic{})
+	if res.Error != nil {
+		logger.Error(ctx, "delete followtopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(res.Error))
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil
+	}
+	err = tx.Model(&followTopicCount).Where("uid = ? and follow_count > 0", uid).Update("follow_count", gorm.Expr("follow_count - 1")).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v delete followtopiccount uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "delete followtopiccount", logger.UID(uid), logger.Err(err))
 		return err
 	}
-	tx.Commit()
-	return nil
+	err = dbAudit(tx, uid, topicID, constant.FollowTypeTopic, AuditUnfollow, "", time.Now())
+	if err != nil {
+		logger.Error(ctx, "add follow_audit", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbUnfollowTopic commit", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+	}
+	return err
+}
+
+// dbGetUnfollowed returns what uid has unfollowed since since, users or
+// topics by followType, most recent first.
+func dbGetUnfollowed(ctx context.Context, uid int64, followType int32, since time.Time, limit int) ([]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetUnfollowed")
+	defer done()
+	var (
+		ids    []int64
+		model  interface{} = &Follow{}
+		column             = "follow_uid"
+	)
+	if followType == constant.FollowTypeTopic {
+		model, column = &FollowTopic{}, "topic_id"
+	}
+	err := dbCli.Unscoped().Model(model).Where("uid = ? AND deleted_at >= ?", uid, since).Order("deleted_at desc").Limit(limit).Pluck(column, &ids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetUnfollowed", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return ids, nil
+}
+
+// dbRestoreFollow brings back the unfollowed edge from uid to toUID and
+// counts it again. It reports false when there is none to bring back.
+func dbRestoreFollow(ctx context.Context, uid, toUID int64) (bool, error) {
+	ctx, done := traceDB(ctx, "dbRestoreFollow")
+	defer done()
+	now := time.Now()
+	followCount := FollowCount{UID: uid, FollowCount: 1}
+	followerCount := FollowCount{UID: toUID, FollowerCount: 1}
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	err := dbRefuseErased(ctx, tx, uid, toUID)
+	if err != nil {
+		return false, err
+	}
+	res := tx.Unscoped().Model(&Follow{}).Where("uid = ? and follow_uid = ? and deleted_at IS NOT NULL", uid, toUID).
+		Updates(map[string]interface{}{"deleted_at": nil, "mtime": now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		if res.Error != nil {
+			logger.Error(ctx, "restore user_follow", logger.UID(uid), logger.TargetID(toUID), logger.Err(res.Error))
+		}
+		return false, res.Error
+	}
+	err = tx.Unscoped().Model(&Follower{}).Where("uid = ? and follower_uid = ? and deleted_at IS NOT NULL", toUID, uid).
+		Updates(map[string]interface{}{"deleted_at": nil, "mtime": now}).Error
+	if err != nil {
+		logger.Error(ctx, "restore user_follower", logger.UID(toUID), logger.TargetID(uid), logger.Err(err))
+		return false, err
+	}
+	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follow_count = follow_count + 1").Create(&followCount).Error
+	if err == nil {
+		err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follower_count = follower_count + 1").Create(&followerCount).Error
+	}
+	if err == nil {
+		stat := FollowStatDaily{UID: toUID, Day: statDay(now), Gained: 1}
+		err = tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE gained = gained + 1").Create(&stat).Error
+	}
+	if err == nil {
+		err = dbAudit(tx, uid, toUID, constant.FollowTypePerson, AuditRestore, "", now)
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbRestoreFollow", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return false, err
+	}
+	return true, nil
+}
+
+func dbRestoreFollowTopic(ctx context.Context, uid, topicID int64) (bool, error) {
+	ctx, done := traceDB(ctx, "dbRestoreFollowTopic")
+	defer done()
+	now := time.Now()
+	followTopicCount := FollowTopicCount{UID: uid, FollowCount: 1}
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	err := dbRefuseErased(ctx, tx, uid)
+	if err != nil {
+		return false, err
+	}
+	res := tx.Unscoped().Model(&FollowTopic{}).Where("uid = ? and topic_id = ? and deleted_at IS NOT NULL", uid, topicID).
+		Updates(map[string]interface{}{"deleted_at": nil, "mtime": now})
+	if res.Error != nil || res.RowsAffected == 0 {
+		if res.Error != nil {
+			logger.Error(ctx, "restore followtopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(res.Error))
+		}
+		return false, res.Error
+	}
+	err = tx.Set("gorm:insert_option", "ON DUPLICATE key update follow_count = follow_count + 1").Create(&followTopicCount).Error
+	if err == nil {
+		err = dbAudit(tx, uid, topicID, constant.FollowTypeTopic, AuditRestore, "", now)
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbRestoreFollowTopic", logger.UID(uid), logger.TargetID(topicID), logger.Err(err))
+		return false, err
+	}
+	return true, nil
+}
+
+// dbGetAudit returns the newest limit changes made by uid.
+func dbGetAudit(ctx context.Context, uid int64, limit int) ([]FollowAudit, error) {
+	ctx, done := traceDB(ctx, "dbGetAudit")
+	defer done()
+	audit := []FollowAudit{}
+	err := dbCli.Where("uid = ?", uid).Order("id desc").Limit(limit).Find(&audit).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetAudit", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return audit, nil
 }
 
 func dbGetFollowCount(ctx context.Context, uid int64) (int64, int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowCount")
+	defer done()
 	followCount := FollowCount{}
-	err := slaveCli.Select([]string{"follow_count", "follower_count"}).Where("uid = ?", uid).Find(&followCount).Error
+	err := readDB(ctx, uid).Select([]string{"follow_count", "follower_count"}).Where("uid = ?", uid).Find(&followCount).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v dbGetFollowCount uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "dbGetFollowCount", logger.UID(uid), logger.Err(err))
 		return 0, 0, err
 	}
 	return followCount.FollowCount, followCount.FollowerCount, nil
 }
 
 func dbGetFollowTopicCount(ctx context.Context, uid int64) (int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowTopicCount")
+	defer done()
 	followTopicCount := FollowTopicCount{}
-	err := slaveCli.Select("follow_count").Where("uid = ?", uid).Find(&followTopicCount).Error
+	err := readDB(ctx, uid).Select("follow_count").Where("uid = ?", uid).Find(&followTopicCount).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v dbGetFollowTopicCount uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "dbGetFollowTopicCount", logger.UID(uid), logger.Err(err))
 		return 0, err
 	}
 	return followTopicCount.FollowCount, nil
 }
 
 func dbGetFollow(ctx context.Context, uid int64) ([]int64, map[int64]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollow")
+	defer done()
 	follows := []Follow{}
-	err := slaveCli.Select([]string{"follow_uid, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&follows).Error
+	err := readDB(ctx, uid).Select([]string{"follow_uid, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&follows).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v db get user follow uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "db get user follow", logger.UID(uid), logger.Err(err))
 		return nil, nil, err
 	}
 	uids := make([]int64, 0, len(follows))
@@ -179,10 +461,12 @@ func dbGetFollow(ctx context.Context, uid int64) ([]int64, map[int64]int64, erro
 }
 
 func dbGetFollower(ctx context.Context, uid int64) ([]int64, map[int64]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollower")
+	defer done()
 	followers := []Follower{}
-	err := slaveCli.Select([]string{"follower_uid, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&followers).Error
+	err := readDB(ctx, uid).Select([]string{"follower_uid, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&followers).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v db get user follower uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "db get user follower", logger.UID(uid), logger.Err(err))
 		return nil, nil, err
 	}
 	uids := make([]int64, 0, len(followers))
@@ -195,10 +479,12 @@ func dbGetFollower(ctx context.Context, uid int64) ([]int64, map[int64]int64, er
 }
 
 func dbGetFollowTopic(ctx context.Context, uid int64) ([]int64, map[int64]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowTopic")
+	defer done()
 	followTopics := []FollowTopic{}
-	err := slaveCli.Select([]string{"topic_id, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&followTopics).Error
+	err := readDB(ctx, uid).Select([]string{"topic_id, ctime"}).Where("uid = ?", uid).Order("id desc").Find(&followTopics).Error
 	if err != nil {
-		global.ExcLog.Printf("ctx %v dbGetFollowTopic uid %v err %v", ctx, uid, err)
+		logger.Error(ctx, "dbGetFollowTopic", logger.UID(uid), logger.Err(err))
 		return nil, nil, err
 	}
 	topicIDs := make([]int64, 0, len(followTopics))
@@ -209,3 +495,666 @@ func dbGetFollowTopic(ctx context.Context, uid int64) ([]int64, map[int64]int64,
 	}
 	return topicIDs, topicMap, nil
 }
+
+// dbGetFollowHead returns the newest limit follows of uid.
+func dbGetFollowHead(ctx context.Context, uid, limit int64) ([]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowHead")
+	defer done()
+	var uids []int64
+	err := readDB(ctx, uid).Model(&Follow{}).Where("uid = ?", uid).Order("id desc").Limit(limit).Pluck("follow_uid", &uids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowHead", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return uids, nil
+}
+
+// dbGetFollowing reports which of ids uid follows.
+func dbGetFollowing(ctx context.Context, uid int64, ids []int64) (map[int64]bool, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowing")
+	defer done()
+	var uids []int64
+	err := readDB(ctx, uid).Model(&Follow{}).Where("uid = ? AND follow_uid IN (?)", uid, ids).Pluck("follow_uid", &uids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowing", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	following := make(map[int64]bool, len(uids))
+	for _, id := range uids {
+		following[id] = true
+	}
+	return following, nil
+}
+
+// dbGetCommon returns the users viewer follows who follow target, newest
+// follow of viewer first: the first limit of them and how many there are.
+func dbGetCommon(ctx context.Context, viewer, target, limit int64) ([]int64, int64, error) {
+	ctx, done := traceDB(ctx, "dbGetCommon")
+	defer done()
+	query := readDB(ctx, viewer).Table("follow f").
+		Joins("JOIN follower r ON r.follower_uid = f.follow_uid AND r.uid = ? AND r.deleted_at IS NULL", target).
+		Where("f.uid = ? AND f.deleted_at IS NULL", viewer)
+	var total int64
+	err := query.Count(&total).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetCommon count", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
+		return nil, 0, err
+	}
+	var uids []int64
+	err = query.Order("f.id desc").Limit(limit).Pluck("f.follow_uid", &uids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetCommon", logger.UID(viewer), logger.TargetID(target), logger.Err(err))
+		return nil, 0, err
+	}
+	return uids, total, nil
+}
+
+// dbUpdateFollowSettings writes the settings of the edge to both of its
+// rows.
+func dbUpdateFollowSettings(ctx context.Context, uid, toUID int64, settings FollowSettings) error {
+	ctx, done := traceDB(ctx, "dbUpdateFollowSettings")
+	defer done()
+	fields := map[string]interface{}{"notify": settings.Notify, "special": settings.Special, "mtime": time.Now()}
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	err := dbRefuseErased(ctx, tx, uid, toUID)
+	if err != nil {
+		return err
+	}
+	// RowsAffected cannot tell a missing edge from settings set again
+	// within the same second, as MySQL counts changed rows
+	following, err := dbLockFollows(tx, uid, []int64{toUID})
+	if err != nil {
+		logger.Error(ctx, "dbUpdateFollowSettings follow", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return err
+	}
+	if !following[toUID] {
+		return notFound("uid %v does not follow %v", uid, toUID)
+	}
+	// gorm leaves the unfollowed rows out of both updates
+	err = tx.Model(&Follow{}).Where("uid = ? and follow_uid = ?", uid, toUID).Updates(fields).Error
+	if err != nil {
+		logger.Error(ctx, "update user_follow settings", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+		return err
+	}
+	err = tx.Model(&Follower{}).Where("uid = ? and follower_uid = ?", toUID, uid).Updates(fields).Error
+	if err != nil {
+		logger.Error(ctx, "update user_follower settings", logger.UID(toUID), logger.TargetID(uid), logger.Err(err))
+		return err
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbUpdateFollowSettings commit", logger.UID(uid), logger.TargetID(toUID), logger.Err(err))
+	}
+	return err
+}
+
+type notifyFollower struct {
+	ID          int64
+	FollowerUID int64
+	Notify      int32
+	Special     bool
+}
+
+// dbGetNotifyFollowers pages through the followers of uid whose notify is
+// one of levels, newest first, starting after the follower row lastID.
+// It reads one row more than limit so the caller knows if more follow.
+func dbGetNotifyFollowers(ctx context.Context, uid int64, levels []int32, specialOnly bool, lastID, limit int64) ([]notifyFollower, error) {
+	ctx, done := traceDB(ctx, "dbGetNotifyFollowers")
+	defer done()
+	query := readDB(ctx, uid).Model(&Follower{}).Where("uid = ? AND notify IN (?)", uid, levels)
+	if specialOnly {
+		query = query.Where("special = ?", true)
+	}
+	if lastID > 0 {
+		query = query.Where("id < ?", lastID)
+	}
+	followers := []notifyFollower{}
+	err := query.Select("id, follower_uid, notify, special").Order("id desc").Limit(limit + 1).Scan(&followers).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetNotifyFollowers", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return followers, nil
+}
+
+// dbGetFollowStats returns the stored days of uid from from to to, both
+// included, oldest first; days without follows or unfollows have no row.
+func dbGetFollowStats(ctx context.Context, uid int64, from, to time.Time) ([]FollowStatDaily, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowStats")
+	defer done()
+	stats := []FollowStatDaily{}
+	err := readDB(ctx, uid).Where("uid = ? AND day BETWEEN ? AND ?", uid, from, to).Order("day").Find(&stats).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowStats", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return stats, nil
+}
+
+// dbGetStatUIDs pages through the users whose followers changed on day,
+// by uid.
+func dbGetStatUIDs(ctx context.Context, day time.Time, afterUID int64, limit int) ([]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetStatUIDs")
+	defer done()
+	var uids []int64
+	err := dbCli.Model(&FollowStatDaily{}).Where("day = ? AND uid > ?", day, afterUID).Order("uid").Limit(limit).Pluck("uid", &uids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetStatUIDs", zap.Time("day", day), logger.Err(err))
+		return nil, err
+	}
+	return uids, nil
+}
+
+// dbGetFollowerCounts is dbGetFollowCount for the follower counters of
+// many users at once.
+func dbGetFollowerCounts(ctx context.Context, uids []int64) (map[int64]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowerCounts")
+	defer done()
+	counts := []FollowCount{}
+	err := dbCli.Select([]string{"uid", "follower_count"}).Where("uid IN (?)", uids).Find(&counts).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowerCounts", zap.Int("uids", len(uids)), logger.Err(err))
+		return nil, err
+	}
+	res := make(map[int64]int64, len(counts))
+	for _, c := range counts {
+		res[c.UID] = c.FollowerCount
+	}
+	return res, nil
+}
+
+// dbSaveFollowerHistory writes points over the ones stored for the same
+// user, granularity and period, unless those were recorded on a later
+// day, so recording an old day again leaves its week and month alone.
+func dbSaveFollowerHistory(ctx context.Context, points []FollowerHistory) error {
+	ctx, done := traceDB(ctx, "dbSaveFollowerHistory")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	for i := range points {
+		err := tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE follower_count = IF(VALUES(as_of) >= as_of, VALUES(follower_count), follower_count), as_of = GREATEST(as_of, VALUES(as_of))").Create(&points[i]).Error
+		if err != nil {
+			logger.Error(ctx, "add follower_history", logger.UID(points[i].UID), zap.String("granularity", points[i].Granularity), logger.Err(err))
+			return err
+		}
+	}
+	err := tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbSaveFollowerHistory commit", zap.Int("points", len(points)), logger.Err(err))
+	}
+	return err
+}
+
+// dbGetFollowerHistory returns the points of uid in the periods from to
+// to, oldest first, led by the last one before from when there is one so
+// the caller knows the count the range starts with.
+func dbGetFollowerHistory(ctx context.Context, uid int64, granularity string, from, to time.Time) ([]FollowerHistory, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowerHistory")
+	defer done()
+	db := readDB(ctx, uid)
+	before := []FollowerHistory{}
+	err := db.Where("uid = ? AND granularity = ? AND period < ?", uid, granularity, from).Order("period desc").Limit(1).Find(&before).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowerHistory before", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	points := []FollowerHistory{}
+	err = db.Where("uid = ? AND granularity = ? AND period BETWEEN ? AND ?", uid, granularity, from, to).Order("period").Find(&points).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowerHistory", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return append(before, points...), nil
+}
+
+// isDuplicate reports whether err is MySQL rejecting a duplicate key.
+func isDuplicate(err error) bool {
+	e, ok := err.(*mysql.MySQLError)
+	return ok && e.Number == mysqlErrDupEntry
+}
+
+func dbCountFollowGroups(ctx context.Context, uid int64) (int64, error) {
+	ctx, done := traceDB(ctx, "dbCountFollowGroups")
+	defer done()
+	var n int64
+	err := dbCli.Model(&FollowGroup{}).Where("uid = ?", uid).Count(&n).Error
+	if err != nil {
+		logger.Error(ctx, "dbCountFollowGroups", logger.UID(uid), logger.Err(err))
+		return 0, err
+	}
+	return n, nil
+}
+
+func dbCreateFollowGroup(ctx context.Context, group *FollowGroup) error {
+	ctx, done := traceDB(ctx, "dbCreateFollowGroup")
+	defer done()
+	err := dbCli.Create(group).Error
+	if err != nil && !isDuplicate(err) {
+		logger.Error(ctx, "dbCreateFollowGroup", logger.UID(group.UID), zap.String("name", group.Name), logger.Err(err))
+	}
+	return err
+}
+
+func dbGetFollowGroups(ctx context.Context, uid int64) ([]FollowGroup, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowGroups")
+	defer done()
+	groups := []FollowGroup{}
+	err := readDB(ctx, uid).Where("uid = ?", uid).Order("id").Find(&groups).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowGroups", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return groups, nil
+}
+
+func dbRenameFollowGroup(ctx context.Context, uid, groupID int64, name string) error {
+	ctx, done := traceDB(ctx, "dbRenameFollowGroup")
+	defer done()
+	err := dbCli.Model(&FollowGroup{}).Where("id = ? and uid = ?", groupID, uid).
+		Updates(map[string]interface{}{"name": name, "mtime": time.Now()}).Error
+	if err != nil && !isDuplicate(err) {
+		logger.Error(ctx, "dbRenameFollowGroup", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+	}
+	return err
+}
+
+func dbDeleteFollowGroup(ctx context.Context, uid, groupID int64) error {
+	ctx, done := traceDB(ctx, "dbDeleteFollowGroup")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	err := tx.Where("group_id = ? and uid = ?", groupID, uid).Delete(&FollowGroupMember{}).Error
+	if err == nil {
+		err = tx.Where("id = ? and uid = ?", groupID, uid).Delete(&FollowGroup{}).Error
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbDeleteFollowGroup", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+	}
+	return err
+}
+
+// dbLockFollows returns which of ids uid follows and locks those follow
+// rows, so they cannot be unfollowed before the transaction ends.
+func dbLockFollows(tx *gorm.DB, uid int64, ids []int64) (map[int64]bool, error) {
+	var followed []int64
+	err := tx.Model(&Follow{}).Set("gorm:query_option", "FOR UPDATE").Where("uid = ? and follow_uid IN (?)", uid, ids).Pluck("follow_uid", &followed).Error
+	if err != nil {
+		return nil, err
+	}
+	following := make(map[int64]bool, len(followed))
+	for _, id := range followed {
+		following[id] = true
+	}
+	return following, nil
+}
+
+// dbAddFollowGroupMembers adds members to a group of uid. Every member
+// must be someone uid follows; the check holds the follow rows until the
+// members are in, and an unfollow takes the member out again after.
+func dbAddFollowGroupMembers(ctx context.Context, uid, groupID int64, members []int64, now time.Time) error {
+	ctx, done := traceDB(ctx, "dbAddFollowGroupMembers")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	following, err := dbLockFollows(tx, uid, members)
+	if err != nil {
+		logger.Error(ctx, "dbAddFollowGroupMembers follow", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		return err
+	}
+	for _, m := range members {
+		if !following[m] {
+			return badRequest("uid %v does not follow %v", uid, m)
+		}
+	}
+	for _, m := range members {
+		member := FollowGroupMember{GroupID: groupID, UID: uid, MemberUID: m, Ctime: now, Mtime: now}
+		err := tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE mtime = VALUES(mtime)").Create(&member).Error
+		if err != nil {
+			logger.Error(ctx, "add follow_group_member", logger.UID(uid), logger.TargetID(groupID), zap.Int64("member", m), logger.Err(err))
+			return err
+		}
+	}
+	err = tx.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbAddFollowGroupMembers commit", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+	}
+	return err
+}
+
+func dbRemoveFollowGroupMembers(ctx context.Context, uid, groupID int64, members []int64) error {
+	ctx, done := traceDB(ctx, "dbRemoveFollowGroupMembers")
+	defer done()
+	err := dbCli.Where("group_id = ? and uid = ? and member_uid IN (?)", groupID, uid, members).Delete(&FollowGroupMember{}).Error
+	if err != nil {
+		logger.Error(ctx, "dbRemoveFollowGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+	}
+	return err
+}
+
+func dbGetFollowGroupMembers(ctx context.Context, uid, groupID int64) ([]int64, map[int64]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetFollowGroupMembers")
+	defer done()
+	members := []FollowGroupMember{}
+	err := readDB(ctx, uid).Select([]string{"member_uid, ctime"}).Where("group_id = ?", groupID).Order("ctime desc").Find(&members).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetFollowGroupMembers", logger.UID(uid), logger.TargetID(groupID), logger.Err(err))
+		return nil, nil, err
+	}
+	uids := make([]int64, 0, len(members))
+	ctimes := make(map[int64]int64, len(members))
+	for _, v := range members {
+		uids = append(uids, v.MemberUID)
+		ctimes[v.MemberUID] = v.Ctime.Unix()
+	}
+	return uids, ctimes, nil
+}
+
+// dbRecount recomputes the counters of uid from the relation tables and
+// overwrites the stored ones.
+func dbRecount(ctx context.Context, uid int64) (Counts, error) {
+	ctx, done := traceDB(ctx, "dbRecount")
+	defer done()
+	var cnt Counts
+	db := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer db.Rollback()
+	err := db.Model(&Follow{}).Where("uid = ?", uid).Count(&cnt.FollowCount).Error
+	if err == nil {
+		err = db.Model(&Follower{}).Where("uid = ?", uid).Count(&cnt.FollowerCount).Error
+	}
+	if err == nil {
+		err = db.Model(&FollowTopic{}).Where("uid = ?", uid).Count(&cnt.FollowTopicCount).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbRecount count", logger.UID(uid), logger.Err(err))
+		return Counts{}, err
+	}
+	followCount := FollowCount{UID: uid, FollowCount: cnt.FollowCount, FollowerCount: cnt.FollowerCount}
+	err = db.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE follow_count = VALUES(follow_count), follower_count = VALUES(follower_count)").Create(&followCount).Error
+	if err != nil {
+		logger.Error(ctx, "dbRecount follow_count", logger.UID(uid), logger.Err(err))
+		return Counts{}, err
+	}
+	topicCount := FollowTopicCount{UID: uid, FollowCount: cnt.FollowTopicCount}
+	err = db.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE follow_count = VALUES(follow_count)").Create(&topicCount).Error
+	if err != nil {
+		logger.Error(ctx, "dbRecount follow_topic_count", logger.UID(uid), logger.Err(err))
+		return Counts{}, err
+	}
+	err = db.Commit().Error
+	if err != nil {
+		logger.Error(ctx, "dbRecount commit", logger.UID(uid), logger.Err(err))
+		return Counts{}, err
+	}
+	return cnt, nil
+}
+
+// dbStartGraphDeletion records that the graph of uid has to be erased,
+// unless it already was, and returns the progress stored for it.
+func dbStartGraphDeletion(ctx context.Context, uid int64) (*GraphDeletion, error) {
+	ctx, done := traceDB(ctx, "dbStartGraphDeletion")
+	defer done()
+	now := time.Now()
+	job := GraphDeletion{UID: uid, Ctime: now, Mtime: now}
+	err := dbCli.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE uid = uid").Create(&job).Error
+	if err != nil {
+		logger.Error(ctx, "dbStartGraphDeletion", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return dbGetGraphDeletion(ctx, uid)
+}
+
+func dbGetGraphDeletion(ctx context.Context, uid int64) (*GraphDeletion, error) {
+	ctx, done := traceDB(ctx, "dbGetGraphDeletion")
+	defer done()
+	var job GraphDeletion
+	err := dbCli.Where("uid = ?", uid).First(&job).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetGraphDeletion", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+// dbGetUnfinishedGraphDeletions returns the uids whose graph is still
+// being erased, oldest request first.
+func dbGetUnfinishedGraphDeletions(ctx context.Context, limit int) ([]int64, error) {
+	ctx, done := traceDB(ctx, "dbGetUnfinishedGraphDeletions")
+	defer done()
+	var uids []int64
+	err := dbCli.Model(&GraphDeletion{}).Where("done = ?", false).Order("ctime").Limit(limit).Pluck("uid", &uids).Error
+	if err != nil {
+		logger.Error(ctx, "dbGetUnfinishedGraphDeletions", logger.Err(err))
+		return nil, err
+	}
+	return uids, nil
+}
+
+// dbGraphDeletionProgress adds n erased rows of column to the progress of
+// uid in the transaction erasing them, so a resumed deletion counts on.
+func dbGraphDeletionProgress(tx *gorm.DB, uid int64, column string, n int) error {
+	return tx.Model(&GraphDeletion{}).Where("uid = ?", uid).
+		Updates(map[string]interface{}{column: gorm.Expr(column+" + ?", n), "mtime": time.Now()}).Error
+}
+
+// dbEraseFollows hard-deletes up to limit follows of uid, unfollowed ones
+// included, with the matching follower rows, and counts the live ones
+// down on the followed side. It returns the users uid still followed and
+// how many rows it found.
+func dbEraseFollows(ctx context.Context, uid int64, limit int) ([]int64, int, error) {
+	ctx, done := traceDB(ctx, "dbEraseFollows")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	rows := []Follow{}
+	err := tx.Unscoped().Set("gorm:query_option", "FOR UPDATE").Select([]string{"follow_uid", "deleted_at"}).
+		Where("uid = ?", uid).Limit(limit).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		if err != nil {
+			logger.Error(ctx, "dbEraseFollows select", logger.UID(uid), logger.Err(err))
+		}
+		return nil, 0, err
+	}
+	ids := make([]int64, 0, len(rows))
+	var live []int64
+	for _, r := range rows {
+		ids = append(ids, r.FollowUID)
+		if r.DeletedAt == nil {
+			live = append(live, r.FollowUID)
+		}
+	}
+	err = tx.Unscoped().Where("uid = ? and follow_uid IN (?)", uid, ids).Delete(&Follow{}).Error
+	if err == nil {
+		err = tx.Unscoped().Where("uid IN (?) and follower_uid = ?", ids, uid).Delete(&Follower{}).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseFollows delete", logger.UID(uid), logger.Err(err))
+		return nil, 0, err
+	}
+	if len(live) > 0 {
+		err = tx.Model(&FollowCount{}).Where("uid IN (?) and follower_count > 0", live).Update("follower_count", gorm.Expr("follower_count-1")).Error
+		if err != nil {
+			logger.Error(ctx, "dbEraseFollows follower_count", logger.UID(uid), logger.Err(err))
+			return nil, 0, err
+		}
+	}
+	day := statDay(time.Now())
+	for _, id := range live {
+		stat := FollowStatDaily{UID: id, Day: day, Lost: 1}
+		err = tx.Set("gorm:insert_option", "ON DUPLICATE KEY UPDATE lost = lost + 1").Create(&stat).Error
+		if err != nil {
+			logger.Error(ctx, "add follow_stat_daily", logger.UID(id), logger.Err(err))
+			return nil, 0, err
+		}
+	}
+	err = dbGraphDeletionProgress(tx, uid, "follows", len(rows))
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseFollows commit", logger.UID(uid), logger.Err(err))
+		return nil, 0, err
+	}
+	return live, len(rows), nil
+}
+
+// dbEraseFollowers is dbEraseFollows for the followers of uid. It also
+// takes uid out of their follow groups and returns those groups by
+// follower.
+func dbEraseFollowers(ctx context.Context, uid int64, limit int) ([]int64, map[int64][]int64, int, error) {
+	ctx, done := traceDB(ctx, "dbEraseFollowers")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	rows := []Follower{}
+	err := tx.Unscoped().Set("gorm:query_option", "FOR UPDATE").Select([]string{"follower_uid", "deleted_at"}).
+		Where("uid = ?", uid).Limit(limit).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		if err != nil {
+			logger.Error(ctx, "dbEraseFollowers select", logger.UID(uid), logger.Err(err))
+		}
+		return nil, nil, 0, err
+	}
+	ids := make([]int64, 0, len(rows))
+	var live []int64
+	for _, r := range rows {
+		ids = append(ids, r.FollowerUID)
+		if r.DeletedAt == nil {
+			live = append(live, r.FollowerUID)
+		}
+	}
+	err = tx.Unscoped().Where("uid = ? and follower_uid IN (?)", uid, ids).Delete(&Follower{}).Error
+	if err == nil {
+		err = tx.Unscoped().Where("uid IN (?) and follow_uid = ?", ids, uid).Delete(&Follow{}).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseFollowers delete", logger.UID(uid), logger.Err(err))
+		return nil, nil, 0, err
+	}
+	if len(live) > 0 {
+		err = tx.Model(&FollowCount{}).Where("uid IN (?) and follow_count > 0", live).Update("follow_count", gorm.Expr("follow_count-1")).Error
+		if err != nil {
+			logger.Error(ctx, "dbEraseFollowers follow_count", logger.UID(uid), logger.Err(err))
+			return nil, nil, 0, err
+		}
+	}
+	members := []FollowGroupMember{}
+	err = tx.Select([]string{"uid", "group_id"}).Where("uid IN (?) and member_uid = ?", ids, uid).Find(&members).Error
+	if err == nil && len(members) > 0 {
+		err = tx.Where("uid IN (?) and member_uid = ?", ids, uid).Delete(&FollowGroupMember{}).Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseFollowers follow_group_member", logger.UID(uid), logger.Err(err))
+		return nil, nil, 0, err
+	}
+	groups := make(map[int64][]int64)
+	for _, m := range members {
+		groups[m.UID] = append(groups[m.UID], m.GroupID)
+	}
+	err = dbGraphDeletionProgress(tx, uid, "followers", len(rows))
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseFollowers commit", logger.UID(uid), logger.Err(err))
+		return nil, nil, 0, err
+	}
+	return live, groups, len(rows), nil
+}
+
+// dbEraseTopics hard-deletes up to limit followed topics of uid and
+// returns how many it found.
+func dbEraseTopics(ctx context.Context, uid int64, limit int) (int, error) {
+	ctx, done := traceDB(ctx, "dbEraseTopics")
+	defer done()
+	tx := dbCli.BeginTx(ctx, &sql.TxOptions{})
+	defer tx.Rollback()
+	var ids []int64
+	err := tx.Unscoped().Model(&FollowTopic{}).Where("uid = ?", uid).Limit(limit).Pluck("topic_id", &ids).Error
+	if err == nil && len(ids) > 0 {
+		err = tx.Unscoped().Where("uid = ? and topic_id IN (?)", uid, ids).Delete(&FollowTopic{}).Error
+	}
+	if err == nil && len(ids) > 0 {
+		err = dbGraphDeletionProgress(tx, uid, "topics", len(ids))
+	}
+	if err == nil {
+		err = tx.Commit().Error
+	}
+	if err != nil {
+		logger.Error(ctx, "dbEraseTopics", logger.UID(uid), logger.Err(err))
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// dbEraseUserRows deletes what is left of uid once its edges are gone:
+// its counters, stats, history, groups and audit trail, including the
+// entries of others following or unfollowing it. Large tables go limit
+// rows at a time, and each runs after every full batch.
+func dbEraseUserRows(ctx context.Context, uid int64, limit int, each func(context.Context) error) error {
+	ctx, done := traceDB(ctx, "dbEraseUserRows")
+	defer done()
+	for _, d := range []struct {
+		table string
+		where string
+		args  []interface{}
+	}{
+		{(&FollowAudit{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowAudit{}).TableName(), "target_id = ? and follow_type = ?", []interface{}{uid, constant.FollowTypePerson}},
+		{(&FollowStatDaily{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowerHistory{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowGroupMember{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowGroup{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowCount{}).TableName(), "uid = ?", []interface{}{uid}},
+		{(&FollowTopicCount{}).TableName(), "uid = ?", []interface{}{uid}},
+	} {
+		query := fmt.Sprintf("DELETE FROM %v WHERE %v LIMIT %v", d.table, d.where, limit)
+		for {
+			res := dbCli.Exec(query, d.args...)
+			if res.Error != nil {
+				logger.Error(ctx, "dbEraseUserRows", logger.UID(uid), zap.String("table", d.table), logger.Err(res.Error))
+				return res.Error
+			}
+			if res.RowsAffected < int64(limit) {
+				break
+			}
+			err := each(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dbHasEdges reports whether any follow, follower or topic row of uid is
+// left, unfollowed ones included.
+func dbHasEdges(ctx context.Context, uid int64) (bool, error) {
+	ctx, done := traceDB(ctx, "dbHasEdges")
+	defer done()
+	for _, model := range []interface{}{&Follow{}, &Follower{}, &FollowTopic{}} {
+		var uids []int64
+		err := dbCli.Unscoped().Model(model).Where("uid = ?", uid).Limit(1).Pluck("uid", &uids).Error
+		if err != nil {
+			logger.Error(ctx, "dbHasEdges", logger.UID(uid), logger.Err(err))
+			return false, err
+		}
+		if len(uids) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func dbFinishGraphDeletion(ctx context.Context, uid int64) error {
+	ctx, done := traceDB(ctx, "dbFinishGraphDeletion")
+	defer done()
+	err := dbCli.Model(&GraphDeletion{}).Where("uid = ?", uid).Updates(map[string]interface{}{"done": true, "mtime": time.Now()}).Error
+	if err != nil {
+		logger.Error(ctx, "dbFinishGraphDeletion", logger.UID(uid), logger.Err(err))
+	}
+	return err
+}
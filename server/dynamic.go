@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"socialservice/conf"
+	"socialservice/util/logger"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultFollowCountTTL = 5 * time.Minute
+	DefaultFollowListTTL  = 30 * time.Minute
+	DefaultRecentWriteTTL = 5 * time.Second
+	DefaultBatchSize      = 1000
+	DefaultBatchSleep     = 500 * time.Millisecond
+	DefaultPageSize       = 10
+	DefaultSuggestFanout  = 100
+	DefaultSuggestTTL     = time.Hour
+	DefaultHistoryTTL     = time.Hour
+)
+
+// tunables is the resolved form of conf.DynamicConf. It is swapped as a
+// whole on reload, so a request never sees half of an update.
+type tunables struct {
+	followCountTTL time.Duration
+	followListTTL  time.Duration
+	recentWriteTTL time.Duration
+	batchSize      int
+	batchSleep     time.Duration
+	pageSize       int64
+	maxPageSize    int64 // 0 for no limit
+	suggestFanout  int64
+	suggestTTL     time.Duration
+	historyTTL     time.Duration
+}
+
+var current atomic.Value // *tunables
+
+func settings() *tunables {
+	return current.Load().(*tunables)
+}
+
+// UpdateConfig applies the dynamic part of a reloaded config. Everything
+// else in config needs a restart to take effect.
+func UpdateConfig(config *conf.Conf) {
+	setTunables(config.Dynamic)
+	logger.Info(context.Background(), "dynamic config applied", zap.Any("dynamic", config.Dynamic))
+}
+
+func setTunables(d conf.DynamicConf) {
+	t := &tunables{
+		followCountTTL: time.Duration(d.FollowCountTTL) * time.Second,
+		followListTTL:  time.Duration(d.FollowListTTL) * time.Second,
+		recentWriteTTL: time.Duration(d.RecentWriteTTL) * time.Second,
+		batchSize:      d.BatchSize,
+		batchSleep:     time.Duration(d.BatchSleep) * time.Millisecond,
+		pageSize:       int64(d.PageSize),
+		maxPageSize:    int64(d.MaxPageSize),
+		suggestFanout:  int64(d.SuggestFanout),
+		suggestTTL:     time.Duration(d.SuggestTTL) * time.Second,
+		historyTTL:     time.Duration(d.HistoryTTL) * time.Second,
+	}
+	if t.followCountTTL == 0 {
+		t.followCountTTL = DefaultFollowCountTTL
+	}
+	if t.followListTTL == 0 {
+		t.followListTTL = DefaultFollowListTTL
+	}
+	if t.recentWriteTTL == 0 {
+		t.recentWriteTTL = DefaultRecentWriteTTL
+	}
+	if t.batchSize == 0 {
+		t.batchSize = DefaultBatchSize
+	}
+	if t.batchSleep == 0 {
+		t.batchSleep = DefaultBatchSleep
+	}
+	if t.pageSize == 0 {
+		t.pageSize = DefaultPageSize
+	}
+	if t.suggestFanout == 0 {
+		t.suggestFanout = DefaultSuggestFanout
+	}
+	if t.suggestTTL == 0 {
+		t.suggestTTL = DefaultSuggestTTL
+	}
+	if t.historyTTL == 0 {
+		t.historyTTL = DefaultHistoryTTL
+	}
+	if t.maxPageSize > 0 && t.pageSize > t.maxPageSize {
+		t.pageSize = t.maxPageSize
+	}
+	current.Store(t)
+}
+
+// pageSize resolves the page size a caller asked for against the defaults
+// and the quota.
+func pageSize(offset int64) int64 {
+	t := settings()
+	if offset <= 0 {
+		return t.pageSize
+	}
+	if t.maxPageSize > 0 && offset > t.maxPageSize {
+		return t.maxPageSize
+	}
+	return offset
+}
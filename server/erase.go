@@ -0,0 +1,261 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"go.uber.org/zap"
+	"socialservice/util/concurrent"
+	"socialservice/util/generate"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"time"
+)
+
+const (
+	// GraphDeletionClaim is how long an instance holds a deletion without
+	// finishing a batch of it, so one that dies frees it that late.
+	GraphDeletionClaim = time.Minute
+	// GraphDeletionSweep is how often instances look for deletions nobody
+	// is running, such as those a restart cut short.
+	GraphDeletionSweep    = time.Minute
+	GraphDeletionSweepMax = 100
+)
+
+// graphDeletionCtx is cancelled on shutdown, leaving unfinished deletions
+// to the sweep.
+var graphDeletionCtx = context.Background()
+
+// errClaimLost stops a run whose claim lapsed, as another instance may
+// have taken the deletion over.
+var errClaimLost = errors.New("graph deletion claim lost")
+
+var (
+	// KEYS[1] claim; ARGV[1] token of the run, ARGV[2] ttl in ms. Only the
+	// run holding the claim extends it.
+	scriptExtendClaim = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+	// KEYS[1] claim; ARGV[1] token of the run. Only the run holding the
+	// claim releases it.
+	scriptReleaseClaim = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+)
+
+// graphClaim is the hold of one run on the deletion of uid.
+type graphClaim struct {
+	uid   int64
+	key   string
+	token string
+}
+
+// claimGraphDeletion returns nil when another run holds the deletion.
+func claimGraphDeletion(ctx context.Context, uid int64) (*graphClaim, error) {
+	c := &graphClaim{uid: uid, key: fmt.Sprintf(RedisKeyGraphDeletion, uid), token: generate.UUID()}
+	claimed, err := redisCli.SetNX(ctx, c.key, c.token, GraphDeletionClaim).Result()
+	if err != nil {
+		logger.Error(ctx, "claim graph deletion", logger.UID(uid), logger.Err(err))
+		return nil, err
+	}
+	if !claimed {
+		return nil, nil
+	}
+	return c, nil
+}
+
+// extend holds the claim for another GraphDeletionClaim. The run calls it
+// after every batch and stops when it fails.
+func (c *graphClaim) extend(ctx context.Context) error {
+	n, err := scriptExtendClaim.Run(ctx, redisCli, []string{c.key}, c.token, GraphDeletionClaim.Milliseconds()).Int()
+	if err != nil {
+		logger.Error(ctx, "extend graph deletion", logger.UID(c.uid), logger.Err(err))
+		return err
+	}
+	if n == 0 {
+		return errClaimLost
+	}
+	return nil
+}
+
+func (c *graphClaim) release(ctx context.Context) {
+	// ctx may be cancelled by now, and the claim should not outlive the run
+	err := scriptReleaseClaim.Run(context.Background(), redisCli, []string{c.key}, c.token).Err()
+	if err != nil {
+		logger.Error(ctx, "release graph deletion", logger.UID(c.uid), logger.Err(err))
+	}
+}
+
+// deleteUserGraph records that the graph of uid has to be erased and
+// starts erasing it in the background unless that is done. It returns the
+// progress so far, so calling it again reports on the same deletion.
+func deleteUserGraph(ctx context.Context, uid int64) (*GraphDeletion, error) {
+	job, err := dbStartGraphDeletion(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Done {
+		concurrent.Go(func() {
+			runGraphDeletion(graphDeletionCtx, uid)
+		})
+	}
+	return job, nil
+}
+
+// EraseUserGraph erases the graph of uid in the foreground, even when it
+// was erased before, and returns the final progress.
+func EraseUserGraph(ctx context.Context, uid int64) (*GraphDeletion, error) {
+	_, err := dbStartGraphDeletion(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	claim, err := claimGraphDeletion(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, conflict("the graph of uid %v is being erased by another instance", uid)
+	}
+	defer claim.release(ctx)
+	err = eraseUserGraph(ctx, claim)
+	if err != nil {
+		return nil, err
+	}
+	return dbGetGraphDeletion(ctx, uid)
+}
+
+// runGraphDeletion erases the graph of uid unless another instance is at
+// it.
+func runGraphDeletion(ctx context.Context, uid int64) {
+	claim, err := claimGraphDeletion(ctx, uid)
+	if err != nil || claim == nil {
+		return
+	}
+	defer claim.release(ctx)
+	start := time.Now()
+	err = eraseUserGraph(ctx, claim)
+	if err != nil {
+		// the deletion stays unfinished for the sweep to resume
+		logger.Error(ctx, "graph deletion", logger.UID(uid), logger.Err(err))
+		return
+	}
+	logger.Info(ctx, "graph deletion", logger.UID(uid), zap.Duration("took", time.Since(start)))
+}
+
+// eraseUserGraph removes the follows, followers and topics of uid a batch
+// at a time, then the rest of its rows and its cached keys, extending
+// claim after every batch. Erased rows are gone, so running it again
+// after a failure carries on where it stopped.
+func eraseUserGraph(ctx context.Context, claim *graphClaim) error {
+	uid := claim.uid
+	for {
+		err := eraseEdges(ctx, claim)
+		if err != nil {
+			return err
+		}
+		// an edge written as the deletion started may have landed behind
+		// the batches
+		left, err := dbHasEdges(ctx, uid)
+		if err != nil {
+			return err
+		}
+		if !left {
+			break
+		}
+	}
+	err := dbEraseUserRows(ctx, uid, settings().batchSize, claim.extend)
+	if err != nil {
+		return err
+	}
+	err = cachePurgeUser(ctx, uid, claim.extend)
+	if err != nil {
+		return err
+	}
+	return dbFinishGraphDeletion(ctx, uid)
+}
+
+func eraseEdges(ctx context.Context, claim *graphClaim) error {
+	for _, erase := range []func(context.Context, int64, int) (int, error){eraseFollows, eraseFollowers, dbEraseTopics} {
+		for {
+			t := settings()
+			n, err := erase(ctx, claim.uid, t.batchSize)
+			if err != nil {
+				return err
+			}
+			if n < t.batchSize {
+				break
+			}
+			err = claim.extend(ctx)
+			if err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(t.batchSleep):
+			}
+		}
+	}
+	return nil
+}
+
+func eraseFollows(ctx context.Context, uid int64, limit int) (int, error) {
+	live, n, err := dbEraseFollows(ctx, uid, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range live {
+		cacheMarkWrite(ctx, id)
+		_ = cacheUnfollow(ctx, uid, id)
+	}
+	return n, nil
+}
+
+func eraseFollowers(ctx context.Context, uid int64, limit int) (int, error) {
+	live, groups, n, err := dbEraseFollowers(ctx, uid, limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range live {
+		cacheMarkWrite(ctx, id)
+		cacheRemFromGroups(ctx, id, uid, groups[id])
+		_ = cacheUnfollow(ctx, id, uid)
+	}
+	return n, nil
+}
+
+// startGraphDeletions resumes, every GraphDeletionSweep until shutdown,
+// the deletions no instance is running.
+func startGraphDeletions() {
+	ctx, cancel := context.WithCancel(context.Background())
+	graphDeletionCtx = ctx
+	lifecycle.OnStop("graph deletions", func(context.Context) error {
+		cancel()
+		return nil
+	})
+	concurrent.Go(func() {
+		ticker := time.NewTicker(GraphDeletionSweep)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			uids, err := dbGetUnfinishedGraphDeletions(ctx, GraphDeletionSweepMax)
+			if err != nil {
+				continue
+			}
+			for _, uid := range uids {
+				runGraphDeletion(ctx, uid)
+			}
+		}
+	})
+}
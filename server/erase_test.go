@@ -0,0 +1,89 @@
+package server
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	merrors "github.com/micro/go-micro/errors"
+	"net/http"
+	"socialservice/util/constant"
+	"testing"
+	"time"
+)
+
+// TestEdgesOfErasedUserRefused checks no edge of a user whose graph is
+// being erased can be added, removed or restored behind the deletion.
+func TestEdgesOfErasedUserRefused(t *testing.T) {
+	env := newTestEnv(t)
+	for name, edit := range map[string]func() error{
+		"follow": func() error {
+			return follow(testCtx, 1, 2, FollowSettings{}, FollowSource{})
+		},
+		"unfollow": func() error {
+			return unfollow(testCtx, 1, 2)
+		},
+		"restore": func() error {
+			_, err := dbRestoreFollow(testCtx, 1, 2)
+			return err
+		},
+		"follow topic": func() error {
+			return followTopic(testCtx, 2, 3)
+		},
+	} {
+		env.sql.ExpectBegin()
+		env.sql.ExpectQuery("FROM `graph_deletion` WHERE .*uid IN .*LOCK IN SHARE MODE").
+			WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(2))
+		env.sql.ExpectRollback()
+		err := edit()
+		if e, ok := err.(*merrors.Error); !ok || e.Code != http.StatusNotFound {
+			t.Errorf("%v of a deleted uid = %v, want not found", name, err)
+		}
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+	// restoreFollows gives up on the first refused edge
+	env.sql.ExpectQuery("FROM `follow` WHERE .*deleted_at >= ").
+		WillReturnRows(sqlmock.NewRows([]string{"follow_uid"}).AddRow(2).AddRow(3))
+	env.sql.ExpectBegin()
+	env.sql.ExpectQuery("FROM `graph_deletion`").WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(1))
+	env.sql.ExpectRollback()
+	if ids, _, err := restoreFollows(testCtx, 1, constant.FollowTypePerson, time.Unix(0, 0)); err == nil || len(ids) != 0 {
+		t.Errorf("restoreFollows of a deleted uid = %v, %v", ids, err)
+	}
+}
+
+// TestGraphClaim checks a run whose claim lapsed and was taken over can
+// neither extend nor release the claim of the run after it.
+func TestGraphClaim(t *testing.T) {
+	env := newTestEnv(t)
+	first, err := claimGraphDeletion(testCtx, 1)
+	if err != nil || first == nil {
+		t.Fatalf("claim = %v, %v", first, err)
+	}
+	if c, err := claimGraphDeletion(testCtx, 1); err != nil || c != nil {
+		t.Fatalf("second claim = %v, %v, want none", c, err)
+	}
+	env.redis.FastForward(GraphDeletionClaim / 2)
+	if err := first.extend(testCtx); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := env.redis.TTL(first.key); ttl != GraphDeletionClaim {
+		t.Errorf("ttl after extend = %v", ttl)
+	}
+
+	env.redis.FastForward(GraphDeletionClaim)
+	second, err := claimGraphDeletion(testCtx, 1)
+	if err != nil || second == nil {
+		t.Fatalf("claim after the first lapsed = %v, %v", second, err)
+	}
+	if err := first.extend(testCtx); err != errClaimLost {
+		t.Errorf("extend of a lapsed claim = %v, want errClaimLost", err)
+	}
+	first.release(testCtx)
+	if got, _ := env.redis.Get(first.key); got != second.token {
+		t.Errorf("the lapsed run released the claim of the next one")
+	}
+	second.release(testCtx)
+	if env.redis.Exists(first.key) {
+		t.Error("claim kept after release")
+	}
+}
@@ -0,0 +1,163 @@
+package server
+
+import (
+	merrors "github.com/micro/go-micro/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"socialservice/rpc/social/pb"
+	"socialservice/util/constant"
+)
+
+// errorID names the service in the errors handed to callers. It is the
+// registered service name once InitService has run.
+var errorID = "social_service"
+
+func badRequest(format string, a ...interface{}) error {
+	return merrors.BadRequest(errorID, format, a...)
+}
+
+func notFound(format string, a ...interface{}) error {
+	return merrors.NotFound(errorID, format, a...)
+}
+
+func conflict(format string, a ...interface{}) error {
+	return merrors.Conflict(errorID, format, a...)
+}
+
+func forbidden(format string, a ...interface{}) error {
+	return merrors.Forbidden(errorID, format, a...)
+}
+
+// rpcError is what every entry point returns: validation errors keep their
+// code and anything from storage becomes an internal error.
+func rpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*merrors.Error); ok {
+		return err
+	}
+	return merrors.InternalServerError(errorID, err.Error())
+}
+
+// httpStatus maps an error from rpcError to the status the gateway answers
+// with.
+func httpStatus(err error) int {
+	if e, ok := err.(*merrors.Error); ok && e.Code >= 400 && e.Code < 600 {
+		return int(e.Code)
+	}
+	return http.StatusInternalServerError
+}
+
+// grpcError maps an error from rpcError to a gRPC status for the native
+// transport.
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	// errors from the stream, such as a client cancelling, already are
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	e, ok := rpcError(err).(*merrors.Error)
+	if !ok {
+		return status.Error(codes.Internal, err.Error())
+	}
+	code := codes.Internal
+	switch e.Code {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusRequestTimeout:
+		code = codes.DeadlineExceeded
+	case http.StatusMethodNotAllowed:
+		code = codes.Unimplemented
+	}
+	return status.Error(code, e.Detail)
+}
+
+func validUID(name string, uid int64) error {
+	if uid <= 0 {
+		return badRequest("%v must be positive, got %v", name, uid)
+	}
+	return nil
+}
+
+func validFollowType(followType int32, allowed ...int32) error {
+	for _, t := range allowed {
+		if followType == t {
+			return nil
+		}
+	}
+	return badRequest("unsupported follow_type %v", followType)
+}
+
+func validFollowItem(item *social_service.FollowItem) error {
+	if item == nil {
+		return badRequest("follow_item is required")
+	}
+	if err := validUID("uid", item.Uid); err != nil {
+		return err
+	}
+	if err := validUID("target_id", item.TargetId); err != nil {
+		return err
+	}
+	if err := validFollowType(item.FollowType, constant.FollowTypePerson, constant.FollowTypeTopic); err != nil {
+		return err
+	}
+	if item.FollowType == constant.FollowTypePerson && item.Uid == item.TargetId {
+		return badRequest("uid %v cannot follow itself", item.Uid)
+	}
+	return nil
+}
+
+func validList(req *social_service.ListRequest, types ...int32) error {
+	if err := validUID("uid", req.Uid); err != nil {
+		return err
+	}
+	if req.LastId < 0 {
+		return badRequest("last_id must not be negative, got %v", req.LastId)
+	}
+	if req.Offset < 0 {
+		return badRequest("offset must not be negative, got %v", req.Offset)
+	}
+	return validFollowType(req.FollowType, types...)
+}
+
+func validGroup(uid, groupID int64) error {
+	if err := validUID("uid", uid); err != nil {
+		return err
+	}
+	return validUID("group_id", groupID)
+}
+
+// validGroupMembers checks a member batch and returns it without
+// duplicates.
+func validGroupMembers(req *social_service.GroupMembersRequest) ([]int64, error) {
+	if err := validGroup(req.Uid, req.GroupId); err != nil {
+		return nil, err
+	}
+	if len(req.MemberUids) == 0 || len(req.MemberUids) > FollowGroupMaxBatch {
+		return nil, badRequest("member_uids must hold 1 to %v uids, got %v", FollowGroupMaxBatch, len(req.MemberUids))
+	}
+	seen := make(map[int64]bool, len(req.MemberUids))
+	members := make([]int64, 0, len(req.MemberUids))
+	for _, m := range req.MemberUids {
+		if err := validUID("member_uids", m); err != nil {
+			return nil, err
+		}
+		if !seen[m] {
+			seen[m] = true
+			members = append(members, m)
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"testing"
+)
+
+func TestGRPCError(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		code codes.Code
+	}{
+		{nil, codes.OK},
+		{status.Error(codes.Canceled, "client went away"), codes.Canceled},
+		{status.Error(codes.DeadlineExceeded, "too slow"), codes.DeadlineExceeded},
+		{badRequest("no"), codes.InvalidArgument},
+		{notFound("gone"), codes.NotFound},
+		{errors.New("mysql down"), codes.Internal},
+	} {
+		if got := status.Code(grpcError(c.err)); got != c.code {
+			t.Errorf("grpcError(%v) = %v, want %v", c.err, got, c.code)
+		}
+	}
+}
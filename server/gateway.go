@@ -0,0 +1,740 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	merrors "github.com/micro/go-micro/errors"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"socialservice/conf"
+	"socialservice/rpc/social/pb"
+	"socialservice/util/constant"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
+	"socialservice/util/tracing"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultGatewayReadTimeout = 10 * time.Second
+	GatewayMaxBody            = 1 << 20
+
+	gatewayUsersPrefix = "/v1/users/"
+)
+
+// ServeGateway serves the SocialServer API as JSON over HTTP until the
+// process shuts down. Every route calls the same handler as the RPC, so
+// validation and errors are identical; errors are answered with their
+// go-micro code as the status and the go-micro error as the body.
+//
+//	POST /v1/follow                        {"uid", "target_id", "follow_type"}
+//	POST /v1/unfollow                      {"uid", "target_id", "follow_type"}
+//	PUT  /v1/follow/settings               {"uid", "target_id", "notify", "special"}
+//	GET  /v1/users/{uid}/follows           ?cursor=&limit=&follow_type=
+//	GET  /v1/users/{uid}/followers         ?cursor=&limit=
+//	GET  /v1/users/{uid}/counts            ?follow_type=
+//	GET  /v1/users/{uid}/follows/all       NDJSON, one {"uids"} line per batch
+//	GET  /v1/users/{uid}/followers/all     NDJSON, one {"uids"} line per batch
+//	GET  /v1/users/{uid}/followers/notify  ?notify=&special_only=&cursor=&limit=
+//	GET  /v1/users/{uid}/suggestions       ?limit=
+//	GET  /v1/users/{uid}/common            ?viewer=&limit=, viewer's follows following uid
+//	GET  /v1/users/{uid}/stats             ?from=&to=, YYYY-MM-DD, followers gained and lost per day
+//	GET  /v1/users/{uid}/history           ?granularity=&from=&to=, follower count per day, week or month
+//
+//	GET    /v1/users/{uid}/groups                  list the follow groups
+//	POST   /v1/users/{uid}/groups                  {"name"}, create one
+//	PUT    /v1/users/{uid}/groups/{gid}            {"name"}, rename it
+//	DELETE /v1/users/{uid}/groups/{gid}
+//	GET    /v1/users/{uid}/groups/{gid}/members    ?cursor=&limit=
+//	POST   /v1/users/{uid}/groups/{gid}/members    {"member_uids"}, add them
+//	DELETE /v1/users/{uid}/groups/{gid}/members    {"member_uids"}, remove them
+//
+// follow_type defaults to a person and notify to every post; /v1/follow
+// takes "notify", "special", "source" and "client" too. With tokens set,
+// every route takes "Authorization: Bearer <token>" like the gRPC server.
+func ServeGateway(config conf.GatewayConf) error {
+	readTimeout := time.Duration(config.ReadTimeout) * time.Second
+	if readTimeout <= 0 {
+		readTimeout = DefaultGatewayReadTimeout
+	}
+	srv := &http.Server{
+		Addr:        config.Addr,
+		Handler:     newGateway(new(SocialService), config.Tokens),
+		ReadTimeout: readTimeout,
+	}
+	lifecycle.OnStop("gateway", srv.Shutdown)
+	err := srv.ListenAndServe()
+	if err == http.ErrServerClosed {
+		return nil
+	}
+	return err
+}
+
+type gateway struct {
+	ss   *SocialService
+	auth tokenAuth
+}
+
+type gatewayHandler func(ctx context.Context, w *gatewayWriter, r *http.Request) error
+
+func newGateway(ss *SocialService, tokens []string) http.Handler {
+	g := &gateway{ss: ss, auth: tokenAuth(tokens)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/follow", func(w http.ResponseWriter, r *http.Request) {
+		g.serve(w, r, "SocialServer.Follow", http.MethodPost, g.follow)
+	})
+	mux.HandleFunc("/v1/unfollow", func(w http.ResponseWriter, r *http.Request) {
+		g.serve(w, r, "SocialServer.Unfollow", http.MethodPost, g.unfollow)
+	})
+	mux.HandleFunc("/v1/follow/settings", func(w http.ResponseWriter, r *http.Request) {
+		g.serve(w, r, "SocialServer.UpdateFollowSettings", http.MethodPut, g.followSettings)
+	})
+	mux.HandleFunc(gatewayUsersPrefix, g.users)
+	return mux
+}
+
+// users routes /v1/users/{uid}/... by what follows the uid.
+func (g *gateway) users(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, gatewayUsersPrefix), "/", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	uid, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		writeGatewayError(r.Context(), &gatewayWriter{ResponseWriter: w}, badRequest("bad uid %q", parts[0]))
+		return
+	}
+	if parts[1] == "groups" || strings.HasPrefix(parts[1], "groups/") {
+		g.groups(w, r, uid, strings.TrimPrefix(strings.TrimPrefix(parts[1], "groups"), "/"))
+		return
+	}
+	var (
+		endpoint string
+		h        gatewayHandler
+	)
+	switch parts[1] {
+	case "follows":
+		endpoint, h = "SocialServer.GetFollow", g.list(uid, g.ss.GetFollow)
+	case "followers":
+		endpoint, h = "SocialServer.GetFollower", g.list(uid, g.ss.GetFollower)
+	case "counts":
+		endpoint, h = "SocialServer.GetFollowCount", g.counts(uid)
+	case "follows/all":
+		endpoint, h = "SocialServer.GetFollowAll", g.followAll(uid)
+	case "followers/all":
+		endpoint, h = "SocialServer.GetFollowerAll", g.followerAll(uid)
+	case "followers/notify":
+		endpoint, h = "SocialServer.GetNotifyFollowers", g.notifyFollowers(uid)
+	case "common":
+		endpoint, h = "SocialServer.GetCommonFollowers", g.common(uid)
+	case "stats":
+		endpoint, h = "SocialServer.GetFollowStats", g.stats(uid)
+	case "history":
+		endpoint, h = "SocialServer.GetFollowerHistory", g.history(uid)
+	case "suggestions":
+		endpoint, h = "SocialServer.GetFollowSuggestions", g.suggestions(uid)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	g.serve(w, r, endpoint, http.MethodGet, h)
+}
+
+// serve gives a gateway call what the handler wrappers give an RPC: a span,
+// the rpc log field and the RPC metrics under the same endpoint name.
+func (g *gateway) serve(w http.ResponseWriter, r *http.Request, endpoint, method string, h gatewayHandler) {
+	gw := &gatewayWriter{ResponseWriter: w}
+	if r.Method != method {
+		gw.Header().Set("Allow", method)
+		writeGatewayError(r.Context(), gw, merrors.MethodNotAllowed(errorID, "%v %v is not allowed", r.Method, r.URL.Path))
+		return
+	}
+	start := time.Now()
+	ctx, span := tracing.StartHTTP(r, endpoint)
+	ctx = logger.With(ctx, zap.String("rpc", endpoint))
+	err := g.authorize(r)
+	if err == nil {
+		err = h(ctx, gw, r)
+	}
+	if err != nil {
+		writeGatewayError(ctx, gw, err)
+	}
+	metrics.ObserveRPC(endpoint, start, err)
+	tracing.End(span, err)
+}
+
+func (g *gateway) authorize(r *http.Request) error {
+	if len(g.auth) == 0 || g.auth.allows(r.Header.Values("Authorization")) {
+		return nil
+	}
+	return merrors.Unauthorized(errorID, "missing or unknown bearer token")
+}
+
+type followBody struct {
+	UID        int64  `json:"uid"`
+	TargetID   int64  `json:"target_id"`
+	FollowType int32  `json:"follow_type"`
+	Notify     int32  `json:"notify"`
+	Special    bool   `json:"special"`
+	Source     string `json:"source"`
+	Client     string `json:"client"`
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, GatewayMaxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("bad request body: %v", err)
+	}
+	return nil
+}
+
+func decodeFollow(w http.ResponseWriter, r *http.Request) (*social_service.FollowRequest, error) {
+	var body followBody
+	if err := decodeBody(w, r, &body); err != nil {
+		return nil, err
+	}
+	if body.FollowType == 0 {
+		body.FollowType = constant.FollowTypePerson
+	}
+	return &social_service.FollowRequest{FollowItem: &social_service.FollowItem{
+		Uid:        body.UID,
+		TargetId:   body.TargetID,
+		FollowType: body.FollowType,
+		Notify:     body.Notify,
+		Special:    body.Special,
+		Source:     body.Source,
+		Client:     body.Client,
+	}}, nil
+}
+
+func (g *gateway) follow(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+	req, err := decodeFollow(w, r)
+	if err != nil {
+		return err
+	}
+	err = g.ss.Follow(ctx, req, &social_service.EmptyResponse{})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (g *gateway) unfollow(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+	req, err := decodeFollow(w, r)
+	if err != nil {
+		return err
+	}
+	err = g.ss.Unfollow(ctx, req, &social_service.EmptyResponse{})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+func (g *gateway) followSettings(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+	req, err := decodeFollow(w, r)
+	if err != nil {
+		return err
+	}
+	err = g.ss.UpdateFollowSettings(ctx, req, &social_service.EmptyResponse{})
+	if err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
+
+// queryInt reads an integer query parameter, def when it is absent.
+func queryInt(r *http.Request, name string, def int64) (int64, error) {
+	val := r.URL.Query().Get(name)
+	if val == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return 0, badRequest("bad %v %q", name, val)
+	}
+	return n, nil
+}
+
+type listBody struct {
+	Uids       []int64 `json:"uids"`
+	HasMore    bool    `json:"has_more"`
+	NextCursor int64   `json:"next_cursor,omitempty"`
+}
+
+func (g *gateway) list(uid int64, call func(context.Context, *social_service.ListRequest, *social_service.ListResponse) error) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		cursor, err := queryInt(r, "cursor", 0)
+		if err != nil {
+			return err
+		}
+		limit, err := queryInt(r, "limit", 0)
+		if err != nil {
+			return err
+		}
+		followType, err := queryInt(r, "follow_type", constant.FollowTypePerson)
+		if err != nil {
+			return err
+		}
+		res := &social_service.ListResponse{}
+		err = call(ctx, &social_service.ListRequest{
+			Uid:        uid,
+			LastId:     cursor,
+			Offset:     limit,
+			FollowType: int32(followType),
+		}, res)
+		if err != nil {
+			return err
+		}
+		body := listBody{Uids: res.Uids, HasMore: res.HasMore}
+		if body.Uids == nil {
+			body.Uids = []int64{}
+		}
+		if res.HasMore {
+			body.NextCursor = cursor + int64(len(res.Uids))
+		}
+		return w.writeJSON(http.StatusOK, body)
+	}
+}
+
+func (g *gateway) counts(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		followType, err := queryInt(r, "follow_type", constant.FollowTypePerson)
+		if err != nil {
+			return err
+		}
+		res := &social_service.CountResponse{}
+		err = g.ss.GetFollowCount(ctx, &social_service.CountRequest{Uid: uid, FollowType: int32(followType)}, res)
+		if err != nil {
+			return err
+		}
+		return w.writeJSON(http.StatusOK, struct {
+			FollowCount   int64 `json:"follow_count"`
+			FollowerCount int64 `json:"follower_count"`
+		}{res.FollowCount, res.FollowerCount})
+	}
+}
+
+type notifyFollowerBody struct {
+	UID     int64 `json:"uid"`
+	Notify  int32 `json:"notify"`
+	Special bool  `json:"special"`
+}
+
+func (g *gateway) notifyFollowers(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		notify, err := queryInt(r, "notify", constant.NotifyAll)
+		if err != nil {
+			return err
+		}
+		cursor, err := queryInt(r, "cursor", 0)
+		if err != nil {
+			return err
+		}
+		limit, err := queryInt(r, "limit", 0)
+		if err != nil {
+			return err
+		}
+		specialOnly := false
+		if val := r.URL.Query().Get("special_only"); val != "" {
+			specialOnly, err = strconv.ParseBool(val)
+			if err != nil {
+				return badRequest("bad special_only %q", val)
+			}
+		}
+		res := &social_service.NotifyFollowersResponse{}
+		err = g.ss.GetNotifyFollowers(ctx, &social_service.NotifyFollowersRequest{
+			Uid:         uid,
+			Notify:      int32(notify),
+			SpecialOnly: specialOnly,
+			LastId:      cursor,
+			Limit:       limit,
+		}, res)
+		if err != nil {
+			return err
+		}
+		body := struct {
+			Followers  []notifyFollowerBody `json:"followers"`
+			HasMore    bool                 `json:"has_more"`
+			NextCursor int64                `json:"next_cursor,omitempty"`
+		}{Followers: make([]notifyFollowerBody, 0, len(res.Followers)), HasMore: res.HasMore}
+		for _, f := range res.Followers {
+			body.Followers = append(body.Followers, notifyFollowerBody{UID: f.Uid, Notify: f.Notify, Special: f.Special})
+		}
+		if res.HasMore {
+			body.NextCursor = res.NextId
+		}
+		return w.writeJSON(http.StatusOK, body)
+	}
+}
+
+type statBody struct {
+	Day    string `json:"day"`
+	Gained int64  `json:"gained"`
+	Lost   int64  `json:"lost"`
+}
+
+func (g *gateway) stats(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		query := r.URL.Query()
+		res := &social_service.FollowStatsResponse{}
+		err := g.ss.GetFollowStats(ctx, &social_service.FollowStatsRequest{Uid: uid, From: query.Get("from"), To: query.Get("to")}, res)
+		if err != nil {
+			return err
+		}
+		stats := make([]statBody, 0, len(res.Stats))
+		for _, s := range res.Stats {
+			stats = append(stats, statBody{Day: s.Day, Gained: s.Gained, Lost: s.Lost})
+		}
+		return w.writeJSON(http.StatusOK, struct {
+			Stats []statBody `json:"stats"`
+		}{stats})
+	}
+}
+
+type historyPointBody struct {
+	Period        string `json:"period"`
+	FollowerCount int64  `json:"follower_count"`
+}
+
+func (g *gateway) history(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		query := r.URL.Query()
+		res := &social_service.FollowerHistoryResponse{}
+		err := g.ss.GetFollowerHistory(ctx, &social_service.FollowerHistoryRequest{
+			Uid:         uid,
+			Granularity: query.Get("granularity"),
+			From:        query.Get("from"),
+			To:          query.Get("to"),
+		}, res)
+		if err != nil {
+			return err
+		}
+		points := make([]historyPointBody, 0, len(res.Points))
+		for _, p := range res.Points {
+			points = append(points, historyPointBody{Period: p.Period, FollowerCount: p.FollowerCount})
+		}
+		return w.writeJSON(http.StatusOK, struct {
+			Points []historyPointBody `json:"points"`
+		}{points})
+	}
+}
+
+type suggestionBody struct {
+	UID    int64 `json:"uid"`
+	Mutual int64 `json:"mutual"`
+}
+
+func (g *gateway) suggestions(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		limit, err := queryInt(r, "limit", 0)
+		if err != nil {
+			return err
+		}
+		res := &social_service.SuggestionResponse{}
+		err = g.ss.GetFollowSuggestions(ctx, &social_service.SuggestionRequest{Uid: uid, Limit: limit}, res)
+		if err != nil {
+			return err
+		}
+		list := make([]suggestionBody, 0, len(res.Suggestions))
+		for _, s := range res.Suggestions {
+			list = append(list, suggestionBody{UID: s.Uid, Mutual: s.Mutual})
+		}
+		return w.writeJSON(http.StatusOK, struct {
+			Suggestions []suggestionBody `json:"suggestions"`
+		}{list})
+	}
+}
+
+func (g *gateway) common(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		viewer, err := queryInt(r, "viewer", 0)
+		if err != nil {
+			return err
+		}
+		limit, err := queryInt(r, "limit", 0)
+		if err != nil {
+			return err
+		}
+		res := &social_service.CommonResponse{}
+		err = g.ss.GetCommonFollowers(ctx, &social_service.CommonRequest{ViewerUid: viewer, TargetUid: uid, Limit: limit}, res)
+		if err != nil {
+			return err
+		}
+		body := struct {
+			Uids  []int64 `json:"uids"`
+			Total int64   `json:"total"`
+		}{res.Uids, res.Total}
+		if body.Uids == nil {
+			body.Uids = []int64{}
+		}
+		return w.writeJSON(http.StatusOK, body)
+	}
+}
+
+func (g *gateway) followAll(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		return g.ss.GetFollowAll(ctx, &social_service.FollowAllRequest{Uid: uid}, &ndjsonStream{w: w})
+	}
+}
+
+func (g *gateway) followerAll(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		return g.ss.GetFollowerAll(ctx, &social_service.FollowAllRequest{Uid: uid}, &ndjsonStream{w: w})
+	}
+}
+
+// groups routes /v1/users/{uid}/groups/... by the rest of the path and the
+// method.
+func (g *gateway) groups(w http.ResponseWriter, r *http.Request, uid int64, rest string) {
+	var (
+		groupID int64
+		members bool
+		allow   string
+	)
+	if rest != "" {
+		parts := strings.SplitN(rest, "/", 2)
+		id, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil || (len(parts) == 2 && parts[1] != "members") {
+			http.NotFound(w, r)
+			return
+		}
+		groupID, members = id, len(parts) == 2
+	}
+	var (
+		endpoint string
+		h        gatewayHandler
+	)
+	switch {
+	case groupID == 0:
+		allow = "GET, POST"
+		switch r.Method {
+		case http.MethodGet:
+			endpoint, h = "SocialServer.GetFollowGroups", g.listGroups(uid)
+		case http.MethodPost:
+			endpoint, h = "SocialServer.CreateFollowGroup", g.createGroup(uid)
+		}
+	case !members:
+		allow = "PUT, DELETE"
+		switch r.Method {
+		case http.MethodPut:
+			endpoint, h = "SocialServer.RenameFollowGroup", g.renameGroup(uid, groupID)
+		case http.MethodDelete:
+			endpoint, h = "SocialServer.DeleteFollowGroup", g.deleteGroup(uid, groupID)
+		}
+	default:
+		allow = "GET, POST, DELETE"
+		switch r.Method {
+		case http.MethodGet:
+			endpoint, h = "SocialServer.GetFollowGroupMembers", g.listGroupMembers(uid, groupID)
+		case http.MethodPost:
+			endpoint, h = "SocialServer.AddFollowGroupMembers", g.changeGroupMembers(uid, groupID, g.ss.AddFollowGroupMembers)
+		case http.MethodDelete:
+			endpoint, h = "SocialServer.RemoveFollowGroupMembers", g.changeGroupMembers(uid, groupID, g.ss.RemoveFollowGroupMembers)
+		}
+	}
+	if h == nil {
+		gw := &gatewayWriter{ResponseWriter: w}
+		gw.Header().Set("Allow", allow)
+		writeGatewayError(r.Context(), gw, merrors.MethodNotAllowed(errorID, "%v %v is not allowed", r.Method, r.URL.Path))
+		return
+	}
+	g.serve(w, r, endpoint, r.Method, h)
+}
+
+type groupBody struct {
+	GroupID int64  `json:"group_id"`
+	UID     int64  `json:"uid"`
+	Name    string `json:"name"`
+	Ctime   int64  `json:"ctime"`
+}
+
+func toGroupBody(group *social_service.FollowGroup) groupBody {
+	return groupBody{GroupID: group.GroupId, UID: group.Uid, Name: group.Name, Ctime: group.Ctime}
+}
+
+func (g *gateway) listGroups(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		res := &social_service.GroupListResponse{}
+		err := g.ss.GetFollowGroups(ctx, &social_service.GroupListRequest{Uid: uid}, res)
+		if err != nil {
+			return err
+		}
+		groups := make([]groupBody, 0, len(res.Groups))
+		for _, group := range res.Groups {
+			groups = append(groups, toGroupBody(group))
+		}
+		return w.writeJSON(http.StatusOK, struct {
+			Groups []groupBody `json:"groups"`
+		}{groups})
+	}
+}
+
+type groupNameBody struct {
+	Name string `json:"name"`
+}
+
+func (g *gateway) createGroup(uid int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		var body groupNameBody
+		if err := decodeBody(w, r, &body); err != nil {
+			return err
+		}
+		res := &social_service.GroupResponse{}
+		err := g.ss.CreateFollowGroup(ctx, &social_service.GroupRequest{Uid: uid, Name: body.Name}, res)
+		if err != nil {
+			return err
+		}
+		return w.writeJSON(http.StatusCreated, toGroupBody(res.Group))
+	}
+}
+
+func (g *gateway) renameGroup(uid, groupID int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		var body groupNameBody
+		if err := decodeBody(w, r, &body); err != nil {
+			return err
+		}
+		err := g.ss.RenameFollowGroup(ctx, &social_service.GroupRequest{Uid: uid, GroupId: groupID, Name: body.Name}, &social_service.EmptyResponse{})
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+func (g *gateway) deleteGroup(uid, groupID int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		err := g.ss.DeleteFollowGroup(ctx, &social_service.GroupRequest{Uid: uid, GroupId: groupID}, &social_service.EmptyResponse{})
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+func (g *gateway) listGroupMembers(uid, groupID int64) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		cursor, err := queryInt(r, "cursor", 0)
+		if err != nil {
+			return err
+		}
+		limit, err := queryInt(r, "limit", 0)
+		if err != nil {
+			return err
+		}
+		res := &social_service.ListResponse{}
+		err = g.ss.GetFollowGroupMembers(ctx, &social_service.GroupMemberListRequest{Uid: uid, GroupId: groupID, LastId: cursor, Offset: limit}, res)
+		if err != nil {
+			return err
+		}
+		body := listBody{Uids: res.Uids, HasMore: res.HasMore}
+		if body.Uids == nil {
+			body.Uids = []int64{}
+		}
+		if res.HasMore {
+			body.NextCursor = cursor + int64(len(res.Uids))
+		}
+		return w.writeJSON(http.StatusOK, body)
+	}
+}
+
+func (g *gateway) changeGroupMembers(uid, groupID int64, call func(context.Context, *social_service.GroupMembersRequest, *social_service.EmptyResponse) error) gatewayHandler {
+	return func(ctx context.Context, w *gatewayWriter, r *http.Request) error {
+		var body struct {
+			MemberUIDs []int64 `json:"member_uids"`
+		}
+		if err := decodeBody(w, r, &body); err != nil {
+			return err
+		}
+		err := call(ctx, &social_service.GroupMembersRequest{Uid: uid, GroupId: groupID, MemberUids: body.MemberUIDs}, &social_service.EmptyResponse{})
+		if err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+}
+
+// gatewayWriter remembers whether the response has started, so an error
+// after the first streamed line is appended to the stream instead.
+type gatewayWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *gatewayWriter) WriteHeader(code int) {
+	w.started = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gatewayWriter) writeJSON(code int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	return json.NewEncoder(w).Encode(v)
+}
+
+func writeGatewayError(ctx context.Context, w *gatewayWriter, err error) {
+	err = rpcError(err)
+	if w.started {
+		// the status is gone already, the error becomes the last line
+		err = json.NewEncoder(w).Encode(struct {
+			Error error `json:"error"`
+		}{err})
+	} else {
+		err = w.writeJSON(httpStatus(err), err)
+	}
+	if err != nil {
+		logger.Debug(ctx, "gateway write", logger.Err(err))
+	}
+}
+
+// ndjsonStream is the server side of GetFollowAll and GetFollowerAll over
+// HTTP: every batch is one JSON line, flushed as soon as it is sent.
+type ndjsonStream struct {
+	w *gatewayWriter
+}
+
+func (s *ndjsonStream) SendMsg(m interface{}) error {
+	if !s.w.started {
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.w.WriteHeader(http.StatusOK)
+	}
+	err := json.NewEncoder(s.w).Encode(m)
+	if err != nil {
+		return err
+	}
+	if f, ok := s.w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+func (s *ndjsonStream) RecvMsg(interface{}) error {
+	return io.EOF
+}
+
+func (s *ndjsonStream) Close() error {
+	return nil
+}
+
+func (s *ndjsonStream) Send(m *social_service.FollowAllResponse) error {
+	uids := m.Uids
+	if uids == nil {
+		uids = []int64{}
+	}
+	return s.SendMsg(struct {
+		Uids []int64 `json:"uids"`
+	}{uids})
+}
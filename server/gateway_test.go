@@ -0,0 +1,38 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGatewayTokens(t *testing.T) {
+	gw := newGateway(new(SocialService), []string{"secret"})
+	for _, c := range []struct {
+		auth string
+		code int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer guess", http.StatusUnauthorized},
+		// past the token check the empty body is refused before any storage
+		{"Bearer secret", http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/v1/follow", strings.NewReader("{}"))
+		if c.auth != "" {
+			r.Header.Set("Authorization", c.auth)
+		}
+		w := httptest.NewRecorder()
+		gw.ServeHTTP(w, r)
+		if w.Code != c.code {
+			t.Errorf("follow with %q = %v, want %v", c.auth, w.Code, c.code)
+		}
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/v1/follow", strings.NewReader("{}"))
+	w := httptest.NewRecorder()
+	newGateway(new(SocialService), nil).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("follow without tokens configured = %v, want %v", w.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"socialservice/util/generate"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	FollowGroupMaxPerUser = 50
+	FollowGroupMaxName    = 32 // characters
+	// FollowGroupMaxBatch is the most members added or removed per call.
+	FollowGroupMaxBatch = 100
+)
+
+// groupName trims name and checks its length.
+func groupName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", badRequest("name is required")
+	}
+	if n := utf8.RuneCountInString(name); n > FollowGroupMaxName {
+		return "", badRequest("name is %v characters, at most %v are allowed", n, FollowGroupMaxName)
+	}
+	return name, nil
+}
+
+func getFollowGroups(ctx context.Context, uid int64) ([]FollowGroup, error) {
+	groups, ok, err := cacheGetFollowGroups(ctx, uid)
+	if err == nil && ok {
+		return groups, nil
+	}
+	groups, err = dbGetFollowGroups(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if !cacheIsRecentWrite(ctx, uid) {
+		backfill(ctx, func(ctx context.Context) {
+			cacheSetFollowGroups(ctx, uid, groups)
+		})
+	}
+	return groups, nil
+}
+
+// findFollowGroup returns the group groupID of uid, or a not found error
+// when uid owns no such group.
+func findFollowGroup(ctx context.Context, uid, groupID int64) (*FollowGroup, error) {
+	groups, err := getFollowGroups(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		if groups[i].ID == groupID {
+			return &groups[i], nil
+		}
+	}
+	return nil, notFound("uid %v has no follow group %v", uid, groupID)
+}
+
+func createFollowGroup(ctx context.Context, uid int64, name string) (*FollowGroup, error) {
+	name, err := groupName(name)
+	if err != nil {
+		return nil, err
+	}
+	n, err := dbCountFollowGroups(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if n >= FollowGroupMaxPerUser {
+		return nil, badRequest("uid %v already has %v follow groups", uid, n)
+	}
+	now := time.Now()
+	group := &FollowGroup{ID: generate.SnowFlask(), UID: uid, Name: name, Ctime: now, Mtime: now}
+	err = dbCreateFollowGroup(ctx, group)
+	if isDuplicate(err) {
+		return nil, conflict("uid %v already has a follow group named %q", uid, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cacheMarkWrite(ctx, uid)
+	cacheDropFollowGroups(ctx, uid)
+	return group, nil
+}
+
+func renameFollowGroup(ctx context.Context, uid, groupID int64, name string) error {
+	name, err := groupName(name)
+	if err != nil {
+		return err
+	}
+	if _, err = findFollowGroup(ctx, uid, groupID); err != nil {
+		return err
+	}
+	err = dbRenameFollowGroup(ctx, uid, groupID, name)
+	if isDuplicate(err) {
+		return conflict("uid %v already has a follow group named %q", uid, name)
+	}
+	if err != nil {
+		return err
+	}
+	cacheMarkWrite(ctx, uid)
+	cacheDropFollowGroups(ctx, uid)
+	return nil
+}
+
+func deleteFollowGroup(ctx context.Context, uid, groupID int64) error {
+	if _, err := findFollowGroup(ctx, uid, groupID); err != nil {
+		return err
+	}
+	err := dbDeleteFollowGroup(ctx, uid, groupID)
+	if err != nil {
+		return err
+	}
+	cacheMarkWrite(ctx, uid)
+	cacheDropFollowGroups(ctx, uid, groupID)
+	return nil
+}
+
+// addFollowGroupMembers adds members to a group of uid. Every member must
+// be someone uid follows.
+func addFollowGroupMembers(ctx context.Context, uid, groupID int64, members []int64) error {
+	if _, err := findFollowGroup(ctx, uid, groupID); err != nil {
+		return err
+	}
+	now := time.Now()
+	err := dbAddFollowGroupMembers(ctx, uid, groupID, members, now)
+	if err != nil {
+		return err
+	}
+	cacheMarkWrite(ctx, uid)
+	return cacheAddGroupMembers(ctx, uid, groupID, members, now.Unix())
+}
+
+func removeFollowGroupMembers(ctx context.Context, uid, groupID int64, members []int64) error {
+	if _, err := findFollowGroup(ctx, uid, groupID); err != nil {
+		return err
+	}
+	err := dbRemoveFollowGroupMembers(ctx, uid, groupID, members)
+	if err != nil {
+		return err
+	}
+	cacheMarkWrite(ctx, uid)
+	return cacheRemGroupMembers(ctx, uid, groupID, members)
+}
+
+// getFollowGroupMembers pages through a group newest member first, lastID
+// being the number of members already read.
+func getFollowGroupMembers(ctx context.Context, uid, groupID, lastID, offset int64) ([]int64, bool, error) {
+	if _, err := findFollowGroup(ctx, uid, groupID); err != nil {
+		return nil, false, err
+	}
+	offset = pageSize(offset)
+	uids, hasMore, ok, err := cacheGetGroupMembers(ctx, uid, groupID, lastID, offset)
+	if err == nil && ok {
+		return uids, hasMore, nil
+	}
+	all, ctimes, err := dbGetFollowGroupMembers(ctx, uid, groupID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !cacheIsRecentWrite(ctx, uid) {
+		backfill(ctx, func(ctx context.Context) {
+			cacheSetGroupMembers(ctx, uid, groupID, all, ctimes)
+		})
+	}
+	uids, hasMore = pageOf(all, lastID, offset)
+	return uids, hasMore, nil
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	merrors "github.com/micro/go-micro/errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func expectLockFollows(env *testEnv, followed ...int64) {
+	rows := sqlmock.NewRows([]string{"follow_uid"})
+	for _, id := range followed {
+		rows.AddRow(id)
+	}
+	env.sql.ExpectQuery("FROM `follow` WHERE .*deleted_at.* IS NULL.*follow_uid IN .*FOR UPDATE").WillReturnRows(rows)
+}
+
+// TestAddFollowGroupMembersChecksFollowsInTx checks the follows of the
+// members are read, locked, in the transaction adding them.
+func TestAddFollowGroupMembersChecksFollowsInTx(t *testing.T) {
+	env := newTestEnv(t)
+	env.sql.ExpectBegin()
+	expectLockFollows(env, 2)
+	env.sql.ExpectRollback()
+	err := dbAddFollowGroupMembers(testCtx, 1, 10, []int64{2, 3}, time.Now())
+	if e, ok := err.(*merrors.Error); !ok || e.Code != http.StatusBadRequest {
+		t.Errorf("adding a member not followed = %v, want bad request", err)
+	}
+
+	env.sql.ExpectBegin()
+	expectLockFollows(env, 2, 3)
+	env.sql.ExpectExec("INSERT INTO `follow_group_member`").WillReturnResult(sqlmock.NewResult(1, 1))
+	env.sql.ExpectExec("INSERT INTO `follow_group_member`").WillReturnResult(sqlmock.NewResult(2, 1))
+	env.sql.ExpectCommit()
+	if err := dbAddFollowGroupMembers(testCtx, 1, 10, []int64{2, 3}, time.Now()); err != nil {
+		t.Error(err)
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
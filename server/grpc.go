@@ -0,0 +1,314 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"net"
+	"socialservice/conf"
+	"socialservice/rpc/social/pb"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
+	"socialservice/util/tracing"
+	"strings"
+)
+
+// ServeGRPC serves SocialServer over plain gRPC on config.Addr until
+// shutdown, for clients that cannot speak the go-micro codecs. It shares
+// the handlers, and so the validation and errors, of the go-micro
+// transport, and also answers grpc.health.v1.
+func ServeGRPC(config conf.GRPCServerConf) error {
+	lis, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		return err
+	}
+	auth := tokenAuth(config.Tokens)
+	s := grpc.NewServer(
+		grpc.UnaryInterceptor(chainUnary(tracing.UnaryServerInterceptor, logger.UnaryServerInterceptor, metrics.UnaryServerInterceptor, auth.unary)),
+		grpc.StreamInterceptor(chainStream(tracing.StreamServerInterceptor, logger.StreamServerInterceptor, metrics.StreamServerInterceptor, auth.stream)),
+	)
+	social_service.RegisterSocialServerServer(s, &grpcServer{ss: new(SocialService)})
+	grpc_health_v1.RegisterHealthServer(s, checker.grpcHealth)
+	if config.Reflection {
+		reflection.Register(s)
+	}
+	lifecycle.OnStop("grpc server", func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			s.Stop()
+			return ctx.Err()
+		}
+	})
+	return s.Serve(lis)
+}
+
+// chainUnary runs interceptors in order, the first outermost. grpc-go only
+// takes one of each kind before ChainUnaryInterceptor.
+func chainUnary(interceptors ...grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			next, interceptor := handler, interceptors[i]
+			handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+				return interceptor(ctx, req, info, next)
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+func chainStream(interceptors ...grpc.StreamServerInterceptor) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			next, interceptor := handler, interceptors[i]
+			handler = func(srv interface{}, ss grpc.ServerStream) error {
+				return interceptor(srv, ss, info, next)
+			}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// tokenAuth accepts calls carrying "authorization: Bearer <token>" with one
+// of its tokens. Without tokens every call is accepted, and health checks
+// always are, since probes carry no token.
+type tokenAuth []string
+
+func (a tokenAuth) check(ctx context.Context, fullMethod string) error {
+	if len(a) == 0 || strings.HasPrefix(fullMethod, "/grpc.health.v1.") {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if a.allows(md.Get("authorization")) {
+		return nil
+	}
+	return status.Error(codes.Unauthenticated, "missing or unknown bearer token")
+}
+
+// allows reports whether one of values, with or without a "Bearer "
+// prefix, is one of the tokens.
+func (a tokenAuth) allows(values []string) bool {
+	for _, v := range values {
+		token := strings.TrimPrefix(v, "Bearer ")
+		for _, t := range a {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a tokenAuth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a tokenAuth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+// grpcServer adapts SocialService to the plain gRPC SocialServerServer.
+type grpcServer struct {
+	ss *SocialService
+}
+
+func (g *grpcServer) Follow(ctx context.Context, req *social_service.FollowRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.Follow(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) Unfollow(ctx context.Context, req *social_service.FollowRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.Unfollow(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollow(ctx context.Context, req *social_service.ListRequest) (*social_service.ListResponse, error) {
+	res := &social_service.ListResponse{}
+	if err := g.ss.GetFollow(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollower(ctx context.Context, req *social_service.ListRequest) (*social_service.ListResponse, error) {
+	res := &social_service.ListResponse{}
+	if err := g.ss.GetFollower(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowCount(ctx context.Context, req *social_service.CountRequest) (*social_service.CountResponse, error) {
+	res := &social_service.CountResponse{}
+	if err := g.ss.GetFollowCount(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowSuggestions(ctx context.Context, req *social_service.SuggestionRequest) (*social_service.SuggestionResponse, error) {
+	res := &social_service.SuggestionResponse{}
+	if err := g.ss.GetFollowSuggestions(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetCommonFollowers(ctx context.Context, req *social_service.CommonRequest) (*social_service.CommonResponse, error) {
+	res := &social_service.CommonResponse{}
+	if err := g.ss.GetCommonFollowers(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) CreateFollowGroup(ctx context.Context, req *social_service.GroupRequest) (*social_service.GroupResponse, error) {
+	res := &social_service.GroupResponse{}
+	if err := g.ss.CreateFollowGroup(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) RenameFollowGroup(ctx context.Context, req *social_service.GroupRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.RenameFollowGroup(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) DeleteFollowGroup(ctx context.Context, req *social_service.GroupRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.DeleteFollowGroup(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowGroups(ctx context.Context, req *social_service.GroupListRequest) (*social_service.GroupListResponse, error) {
+	res := &social_service.GroupListResponse{}
+	if err := g.ss.GetFollowGroups(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) AddFollowGroupMembers(ctx context.Context, req *social_service.GroupMembersRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.AddFollowGroupMembers(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) RemoveFollowGroupMembers(ctx context.Context, req *social_service.GroupMembersRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.RemoveFollowGroupMembers(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowGroupMembers(ctx context.Context, req *social_service.GroupMemberListRequest) (*social_service.ListResponse, error) {
+	res := &social_service.ListResponse{}
+	if err := g.ss.GetFollowGroupMembers(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) UpdateFollowSettings(ctx context.Context, req *social_service.FollowRequest) (*social_service.EmptyResponse, error) {
+	res := &social_service.EmptyResponse{}
+	if err := g.ss.UpdateFollowSettings(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetNotifyFollowers(ctx context.Context, req *social_service.NotifyFollowersRequest) (*social_service.NotifyFollowersResponse, error) {
+	res := &social_service.NotifyFollowersResponse{}
+	if err := g.ss.GetNotifyFollowers(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowStats(ctx context.Context, req *social_service.FollowStatsRequest) (*social_service.FollowStatsResponse, error) {
+	res := &social_service.FollowStatsResponse{}
+	if err := g.ss.GetFollowStats(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowerHistory(ctx context.Context, req *social_service.FollowerHistoryRequest) (*social_service.FollowerHistoryResponse, error) {
+	res := &social_service.FollowerHistoryResponse{}
+	if err := g.ss.GetFollowerHistory(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) RestoreFollows(ctx context.Context, req *social_service.RestoreRequest) (*social_service.RestoreResponse, error) {
+	res := &social_service.RestoreResponse{}
+	if err := g.ss.RestoreFollows(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) DeleteUserGraph(ctx context.Context, req *social_service.GraphDeletionRequest) (*social_service.GraphDeletionResponse, error) {
+	res := &social_service.GraphDeletionResponse{}
+	if err := g.ss.DeleteUserGraph(ctx, req, res); err != nil {
+		return nil, grpcError(err)
+	}
+	return res, nil
+}
+
+func (g *grpcServer) GetFollowAll(req *social_service.FollowAllRequest, stream social_service.SocialServer_GetFollowAllServer) error {
+	return grpcError(g.ss.GetFollowAll(stream.Context(), req, grpcStream{stream}))
+}
+
+func (g *grpcServer) GetFollowerAll(req *social_service.FollowAllRequest, stream social_service.SocialServer_GetFollowerAllServer) error {
+	return grpcError(g.ss.GetFollowerAll(stream.Context(), req, grpcStream{stream}))
+}
+
+type followAllServer interface {
+	Send(*social_service.FollowAllResponse) error
+	grpc.ServerStream
+}
+
+// grpcStream gives a gRPC server stream the go-micro stream interface the
+// handlers send on.
+type grpcStream struct {
+	followAllServer
+}
+
+func (s grpcStream) Close() error {
+	return nil
+}
@@ -2,77 +2,167 @@ package server
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"github.com/bradfitz/gomemcache/memcache"
 	"github.com/go-redis/redis/v8"
 	"github.com/jinzhu/gorm"
 	_ "github.com/jinzhu/gorm/dialects/mysql"
+	"github.com/micro/go-micro/metadata"
+	grpcmd "google.golang.org/grpc/metadata"
 	"socialservice/conf"
 	"socialservice/rpc/social/pb"
+	"socialservice/util/concurrent"
 	"socialservice/util/constant"
+	"socialservice/util/generate"
+	"socialservice/util/lifecycle"
+	"socialservice/util/metrics"
+	"socialservice/util/tracing"
+	"strings"
+	"time"
 )
 
 type SocialService struct {
 }
 
+// AdminTokenHeader carries the token of the admin RPCs. It is not
+// "authorization", which the gRPC transport checks against its own tokens.
+const AdminTokenHeader = "x-admin-token"
+
 var (
-	mcCli    *memcache.Client
-	redisCli redis.Cmdable
-	dbCli    *gorm.DB
-	slaveCli *gorm.DB
+	mcCli        *memcache.Client
+	redisCli     redis.Cmdable
+	redisCluster *redis.ClusterClient
+	dbCli        *gorm.DB
+	slaves       *replicaSet
+	backfillPool *concurrent.Pool
+	// adminTokens are the tokens RestoreFollows and DeleteUserGraph take,
+	// on either transport.
+	adminTokens tokenAuth
 )
 
 func InitService(config *conf.Conf) error {
-	var err error
+	errorID = config.Grpc.Name
+	adminTokens = tokenAuth(config.Admin.Tokens)
+	err := InitStorage(config)
+	if err != nil {
+		return err
+	}
+	initHealth(config)
+	startGraphDeletions()
+	if config.History.SnapshotAt != "" {
+		return startHistorySnapshot(config.History.SnapshotAt)
+	}
+	return nil
+}
+
+// InitStorage sets up the MySQL, Redis and memcached clients and the
+// caches in front of them, without the probes only a serving instance
+// needs. Tools that reuse this package's functions start from here.
+func InitStorage(config *conf.Conf) error {
+	setTunables(config.Dynamic)
+	err := generate.InitSnowFlask()
+	if err != nil {
+		return err
+	}
 	mcCli = conf.GetMC(config.MC.Addr)
-	redisCli = conf.GetRedisCluster(config.RedisCluster.Addr)
-	dbCli, err = conf.GetGorm(fmt.Sprintf(conf.MysqlAddr, config.Mysql.User, config.Mysql.Password, config.Mysql.Host, config.Mysql.Port, config.Mysql.DB))
+	switch config.CountCache {
+	case "", CountCacheRedis:
+	case CountCacheMemcache:
+		countCache = CountCacheMemcache
+	default:
+		return fmt.Errorf("unknown count_cache %q", config.CountCache)
+	}
+	redisCluster = conf.GetRedisCluster(config.RedisCluster.Addr)
+	redisCluster.AddHook(tracing.RedisHook{})
+	redisCli = redisCluster
+	lifecycle.OnStop("redis", func(ctx context.Context) error {
+		return redisCluster.Close()
+	})
+	initLocalCache(config.LocalCache)
+	backfillPool = concurrent.NewPool("backfill", concurrent.PoolOptions{
+		Workers:     config.Backfill.Workers,
+		QueueSize:   config.Backfill.Queue,
+		Overflow:    config.Backfill.Overflow,
+		TaskTimeout: time.Duration(config.Backfill.Timeout) * time.Second,
+	})
+	metrics.RegisterPool("backfill", func() float64 {
+		return float64(backfillPool.Queued())
+	})
+	lifecycle.OnStop("backfill pool", backfillPool.Close)
+	dbCli, err = conf.GetGorm(config.Mysql)
 	if err != nil {
 		return err
 	}
-	slaveCli, err = conf.GetGorm(fmt.Sprintf(conf.MysqlAddr, config.Slave.User, config.Slave.Password, config.Slave.Host, config.Slave.Port, config.Slave.DB))
-	return err
+	lifecycle.OnStop("mysql master", func(ctx context.Context) error {
+		return dbCli.Close()
+	})
+	slaves, err = newReplicaSet(config)
+	if err != nil {
+		return err
+	}
+	lifecycle.OnStop("mysql slaves", func(ctx context.Context) error {
+		return slaves.close()
+	})
+	slaves.start()
+	return nil
 }
 
 func (ss *SocialService) Follow(ctx context.Context, req *social_service.FollowRequest, res *social_service.EmptyResponse) error {
+	err := validFollowItem(req.FollowItem)
+	if err != nil {
+		return err
+	}
 	switch req.FollowItem.FollowType {
 	case constant.FollowTypePerson:
-		return follow(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
+		var (
+			settings FollowSettings
+			source   FollowSource
+		)
+		settings, err = followSettings(req.FollowItem.Notify, req.FollowItem.Special)
+		if err == nil {
+			source, err = followSource(req.FollowItem.Source, req.FollowItem.Client)
+		}
+		if err != nil {
+			return err
+		}
+		err = follow(ctx, req.FollowItem.Uid, req.FollowItem.TargetId, settings, source)
 	case constant.FollowTypeTopic:
-		return followTopic(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
-	default:
-		return errors.New("parameter error")
+		err = followTopic(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
 	}
+	return rpcError(err)
 }
 
 func (ss *SocialService) Unfollow(ctx context.Context, req *social_service.FollowRequest, res *social_service.EmptyResponse) error {
+	err := validFollowItem(req.FollowItem)
+	if err != nil {
+		return err
+	}
 	switch req.FollowItem.FollowType {
 	case constant.FollowTypePerson:
-		return unfollow(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
+		err = unfollow(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
 	case constant.FollowTypeTopic:
-		return unfollowTopic(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
-	default:
-		return errors.New("parameter error")
+		err = unfollowTopic(ctx, req.FollowItem.Uid, req.FollowItem.TargetId)
 	}
+	return rpcError(err)
 }
 
 func (ss *SocialService) GetFollow(ctx context.Context, req *social_service.ListRequest, res *social_service.ListResponse) error {
+	err := validList(req, constant.FollowTypePerson, constant.FollowTypeTopic)
+	if err != nil {
+		return err
+	}
 	var (
 		ids     []int64
 		hasMore bool
-		err     error
 	)
 	switch req.FollowType {
 	case constant.FollowTypePerson:
 		ids, hasMore, err = getFollow(ctx, req.Uid, req.LastId, req.Offset)
 	case constant.FollowTypeTopic:
 		ids, hasMore, err = getFollowTopic(ctx, req.Uid, req.LastId, req.Offset)
-	default:
-		return errors.New("parameter error")
 	}
 	if err != nil {
-		return err
+		return rpcError(err)
 	}
 	res.Uids = ids
 	res.HasMore = hasMore
@@ -80,37 +170,39 @@ func (ss *SocialService) GetFollow(ctx context.Context, req *social_service.List
 }
 
 func (ss *SocialService) GetFollower(ctx context.Context, req *social_service.ListRequest, res *social_service.ListResponse) error {
-	switch req.FollowType {
-	case constant.FollowTypePerson:
-		uids, hasMore, err := getFollower(ctx, req.Uid, req.LastId, req.Offset)
-		if err != nil {
-			return err
-		}
-		res.Uids = uids
-		res.HasMore = hasMore
-		return nil
-	default:
-		return errors.New("parameter error")
+	err := validList(req, constant.FollowTypePerson)
+	if err != nil {
+		return err
 	}
+	uids, hasMore, err := getFollower(ctx, req.Uid, req.LastId, req.Offset)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Uids = uids
+	res.HasMore = hasMore
+	return nil
 }
 
 func (ss *SocialService) GetFollowCount(ctx context.Context, req *social_service.CountRequest, res *social_service.CountResponse) error {
+	err := validUID("uid", req.Uid)
+	if err == nil {
+		err = validFollowType(req.FollowType, constant.FollowTypePerson, constant.FollowTypeTopic)
+	}
+	if err != nil {
+		return err
+	}
 	var (
 		followCnt   int64
 		followerCnt int64
-		err         error
 	)
-
 	switch req.FollowType {
 	case constant.FollowTypePerson:
 		followCnt, followerCnt, err = getFollowCount(ctx, req.Uid)
 	case constant.FollowTypeTopic:
 		followCnt, err = getFollowTopicCount(ctx, req.Uid)
-	default:
-		return errors.New("parameter error")
 	}
 	if err != nil {
-		return err
+		return rpcError(err)
 	}
 	res.FollowCount = followCnt
 	res.FollowerCount = followerCnt
@@ -125,10 +217,14 @@ func (ss *SocialService) GetFollowAll(ctx context.Context, res *social_service.F
 		err    error
 	)
 	uid = res.Uid
+	err = validUID("uid", uid)
+	if err != nil {
+		return err
+	}
 	for {
 		uids, cursor, err = getAllFollow(ctx, uid, cursor)
 		if err != nil {
-			return err
+			return rpcError(err)
 		}
 		err = stream.Send(&social_service.FollowAllResponse{Uids: uids})
 		if err != nil {
@@ -149,10 +245,14 @@ func (ss *SocialService) GetFollowerAll(ctx context.Context, res *social_service
 		err    error
 	)
 	uid = res.Uid
+	err = validUID("uid", uid)
+	if err != nil {
+		return err
+	}
 	for {
 		uids, cursor, err = getAllFollower(ctx, uid, cursor)
 		if err != nil {
-			return err
+			return rpcError(err)
 		}
 		err = stream.Send(&social_service.FollowAllResponse{Uids: uids})
 		if err != nil {
@@ -164,3 +264,286 @@ func (ss *SocialService) GetFollowerAll(ctx context.Context, res *social_service
 	}
 	return nil
 }
+
+func (ss *SocialService) GetFollowSuggestions(ctx context.Context, req *social_service.SuggestionRequest, res *social_service.SuggestionResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	if req.Limit < 0 {
+		return badRequest("limit must not be negative, got %v", req.Limit)
+	}
+	list, err := getFollowSuggestions(ctx, req.Uid, req.Limit)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Suggestions = make([]*social_service.Suggestion, 0, len(list))
+	for _, s := range list {
+		res.Suggestions = append(res.Suggestions, &social_service.Suggestion{Uid: s.uid, Mutual: s.mutual})
+	}
+	return nil
+}
+
+func (ss *SocialService) GetCommonFollowers(ctx context.Context, req *social_service.CommonRequest, res *social_service.CommonResponse) error {
+	err := validUID("viewer_uid", req.ViewerUid)
+	if err == nil {
+		err = validUID("target_uid", req.TargetUid)
+	}
+	if err != nil {
+		return err
+	}
+	if req.Limit < 0 {
+		return badRequest("limit must not be negative, got %v", req.Limit)
+	}
+	uids, total, err := getCommonFollowers(ctx, req.ViewerUid, req.TargetUid, req.Limit)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Uids = uids
+	res.Total = total
+	return nil
+}
+
+func toFollowGroup(g *FollowGroup) *social_service.FollowGroup {
+	return &social_service.FollowGroup{GroupId: g.ID, Uid: g.UID, Name: g.Name, Ctime: g.Ctime.Unix()}
+}
+
+func (ss *SocialService) CreateFollowGroup(ctx context.Context, req *social_service.GroupRequest, res *social_service.GroupResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	group, err := createFollowGroup(ctx, req.Uid, req.Name)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Group = toFollowGroup(group)
+	return nil
+}
+
+func (ss *SocialService) RenameFollowGroup(ctx context.Context, req *social_service.GroupRequest, res *social_service.EmptyResponse) error {
+	err := validGroup(req.Uid, req.GroupId)
+	if err != nil {
+		return err
+	}
+	return rpcError(renameFollowGroup(ctx, req.Uid, req.GroupId, req.Name))
+}
+
+func (ss *SocialService) DeleteFollowGroup(ctx context.Context, req *social_service.GroupRequest, res *social_service.EmptyResponse) error {
+	err := validGroup(req.Uid, req.GroupId)
+	if err != nil {
+		return err
+	}
+	return rpcError(deleteFollowGroup(ctx, req.Uid, req.GroupId))
+}
+
+func (ss *SocialService) GetFollowGroups(ctx context.Context, req *social_service.GroupListRequest, res *social_service.GroupListResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	groups, err := getFollowGroups(ctx, req.Uid)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Groups = make([]*social_service.FollowGroup, 0, len(groups))
+	for i := range groups {
+		res.Groups = append(res.Groups, toFollowGroup(&groups[i]))
+	}
+	return nil
+}
+
+func (ss *SocialService) AddFollowGroupMembers(ctx context.Context, req *social_service.GroupMembersRequest, res *social_service.EmptyResponse) error {
+	members, err := validGroupMembers(req)
+	if err != nil {
+		return err
+	}
+	return rpcError(addFollowGroupMembers(ctx, req.Uid, req.GroupId, members))
+}
+
+func (ss *SocialService) RemoveFollowGroupMembers(ctx context.Context, req *social_service.GroupMembersRequest, res *social_service.EmptyResponse) error {
+	members, err := validGroupMembers(req)
+	if err != nil {
+		return err
+	}
+	return rpcError(removeFollowGroupMembers(ctx, req.Uid, req.GroupId, members))
+}
+
+func (ss *SocialService) GetFollowGroupMembers(ctx context.Context, req *social_service.GroupMemberListRequest, res *social_service.ListResponse) error {
+	err := validGroup(req.Uid, req.GroupId)
+	if err != nil {
+		return err
+	}
+	if req.LastId < 0 || req.Offset < 0 {
+		return badRequest("last_id and offset must not be negative")
+	}
+	uids, hasMore, err := getFollowGroupMembers(ctx, req.Uid, req.GroupId, req.LastId, req.Offset)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Uids = uids
+	res.HasMore = hasMore
+	return nil
+}
+
+func (ss *SocialService) UpdateFollowSettings(ctx context.Context, req *social_service.FollowRequest, res *social_service.EmptyResponse) error {
+	err := validFollowItem(req.FollowItem)
+	if err == nil {
+		err = validFollowType(req.FollowItem.FollowType, constant.FollowTypePerson)
+	}
+	if err != nil {
+		return err
+	}
+	settings, err := followSettings(req.FollowItem.Notify, req.FollowItem.Special)
+	if err != nil {
+		return err
+	}
+	return rpcError(updateFollowSettings(ctx, req.FollowItem.Uid, req.FollowItem.TargetId, settings))
+}
+
+func (ss *SocialService) GetNotifyFollowers(ctx context.Context, req *social_service.NotifyFollowersRequest, res *social_service.NotifyFollowersResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	if req.LastId < 0 || req.Limit < 0 {
+		return badRequest("last_id and limit must not be negative")
+	}
+	followers, hasMore, nextID, err := getNotifyFollowers(ctx, req.Uid, req.Notify, req.SpecialOnly, req.LastId, req.Limit)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Followers = make([]*social_service.FollowItem, 0, len(followers))
+	for _, f := range followers {
+		res.Followers = append(res.Followers, &social_service.FollowItem{
+			Uid:        f.FollowerUID,
+			TargetId:   req.Uid,
+			FollowType: constant.FollowTypePerson,
+			Notify:     f.Notify,
+			Special:    f.Special,
+		})
+	}
+	res.HasMore = hasMore
+	res.NextId = nextID
+	return nil
+}
+
+func (ss *SocialService) GetFollowStats(ctx context.Context, req *social_service.FollowStatsRequest, res *social_service.FollowStatsResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	from, to, err := parseDayRange(req.From, req.To, FollowStatsMaxDays)
+	if err != nil {
+		return err
+	}
+	stats, err := getFollowStats(ctx, req.Uid, from, to)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Stats = make([]*social_service.FollowStat, 0, len(stats))
+	for _, s := range stats {
+		res.Stats = append(res.Stats, &social_service.FollowStat{Day: s.Day.Format(statDayLayout), Gained: s.Gained, Lost: s.Lost})
+	}
+	return nil
+}
+
+func (ss *SocialService) GetFollowerHistory(ctx context.Context, req *social_service.FollowerHistoryRequest, res *social_service.FollowerHistoryResponse) error {
+	err := validUID("uid", req.Uid)
+	if err != nil {
+		return err
+	}
+	if req.Granularity == "" {
+		req.Granularity = HistoryDay
+	}
+	maxDays, ok := historyMaxDays[req.Granularity]
+	if !ok {
+		return badRequest("unsupported granularity %q", req.Granularity)
+	}
+	from, to, err := parseDayRange(req.From, req.To, maxDays)
+	if err != nil {
+		return err
+	}
+	points, err := getFollowerHistory(ctx, req.Uid, req.Granularity, from, to)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Points = make([]*social_service.FollowerHistoryPoint, 0, len(points))
+	for _, p := range points {
+		res.Points = append(res.Points, &social_service.FollowerHistoryPoint{Period: p.Period.Format(statDayLayout), FollowerCount: p.FollowerCount})
+	}
+	return nil
+}
+
+// requireAdmin refuses the call unless it carries one of adminTokens. The
+// admin RPCs share the public service, so with no tokens set they are off.
+func requireAdmin(ctx context.Context) error {
+	if len(adminTokens) == 0 {
+		return forbidden("admin RPCs are disabled, set admin.tokens to enable them")
+	}
+	md, _ := grpcmd.FromIncomingContext(ctx)
+	values := md.Get(AdminTokenHeader)
+	if mmd, ok := metadata.FromContext(ctx); ok {
+		// go-micro transports may canonicalise the key
+		for k, v := range mmd {
+			if strings.EqualFold(k, AdminTokenHeader) {
+				values = append(values, v)
+			}
+		}
+	}
+	if !adminTokens.allows(values) {
+		return forbidden("missing or unknown %v", AdminTokenHeader)
+	}
+	return nil
+}
+
+// RestoreFollows is for operators undoing an accidental mass unfollow;
+// since is a unix time.
+func (ss *SocialService) RestoreFollows(ctx context.Context, req *social_service.RestoreRequest, res *social_service.RestoreResponse) error {
+	err := requireAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = validUID("uid", req.Uid)
+	if err == nil {
+		err = validFollowType(req.FollowType, constant.FollowTypePerson, constant.FollowTypeTopic)
+	}
+	if err != nil {
+		return err
+	}
+	if req.Since <= 0 {
+		return badRequest("since must be positive, got %v", req.Since)
+	}
+	ids, hasMore, err := restoreFollows(ctx, req.Uid, req.FollowType, time.Unix(req.Since, 0))
+	if err != nil {
+		return rpcError(err)
+	}
+	res.TargetIds = ids
+	res.HasMore = hasMore
+	return nil
+}
+
+// DeleteUserGraph erases every edge of uid in both directions after its
+// account was deleted. The work runs in the background; the response is
+// its progress, and calling again reports it anew.
+func (ss *SocialService) DeleteUserGraph(ctx context.Context, req *social_service.GraphDeletionRequest, res *social_service.GraphDeletionResponse) error {
+	err := requireAdmin(ctx)
+	if err == nil {
+		err = validUID("uid", req.Uid)
+	}
+	if err != nil {
+		return err
+	}
+	job, err := deleteUserGraph(ctx, req.Uid)
+	if err != nil {
+		return rpcError(err)
+	}
+	res.Uid = job.UID
+	res.Done = job.Done
+	res.Follows = job.Follows
+	res.Followers = job.Followers
+	res.Topics = job.Topics
+	res.Ctime = job.Ctime.Unix()
+	res.Mtime = job.Mtime.Unix()
+	return nil
+}
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	merrors "github.com/micro/go-micro/errors"
+	"github.com/micro/go-micro/metadata"
+	grpcmd "google.golang.org/grpc/metadata"
+	"net/http"
+	"socialservice/rpc/social/pb"
+	"testing"
+)
+
+func TestAdminRPCsNeedToken(t *testing.T) {
+	defer func() {
+		adminTokens = nil
+	}()
+	// uid 0 is refused by validation, so a call past the token check
+	// touches no storage
+	call := func(ctx context.Context) error {
+		ss := new(SocialService)
+		for _, err := range []error{
+			ss.RestoreFollows(ctx, &social_service.RestoreRequest{}, &social_service.RestoreResponse{}),
+			ss.DeleteUserGraph(ctx, &social_service.GraphDeletionRequest{}, &social_service.GraphDeletionResponse{}),
+		} {
+			if e, ok := err.(*merrors.Error); ok && e.Code == http.StatusForbidden {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if call(context.Background()) == nil {
+		t.Error("admin RPC accepted with no admin tokens set")
+	}
+	adminTokens = tokenAuth{"secret"}
+	for name, ctx := range map[string]context.Context{
+		"no token":      context.Background(),
+		"wrong token":   grpcmd.NewIncomingContext(context.Background(), grpcmd.Pairs(AdminTokenHeader, "guess")),
+		"authorization": grpcmd.NewIncomingContext(context.Background(), grpcmd.Pairs("authorization", "Bearer secret")),
+	} {
+		if call(ctx) == nil {
+			t.Errorf("%v: admin RPC accepted", name)
+		}
+	}
+	for name, ctx := range map[string]context.Context{
+		"grpc":     grpcmd.NewIncomingContext(context.Background(), grpcmd.Pairs(AdminTokenHeader, "Bearer secret")),
+		"go-micro": metadata.NewContext(context.Background(), metadata.Metadata{"X-Admin-Token": "secret"}),
+	} {
+		if err := call(ctx); err != nil {
+			t.Errorf("%v: %v", name, err)
+		}
+	}
+}
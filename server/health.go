@@ -0,0 +1,313 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/micro/go-micro/registry"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"socialservice/conf"
+	"socialservice/util/concurrent"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHealthInterval = 10 * time.Second
+	HealthCheckTimeout    = 2 * time.Second
+	// HealthStaleRounds is how many intervals may pass without a finished
+	// round before /healthz reports the process as stuck.
+	HealthStaleRounds = 3
+
+	DependencyMysqlMaster = "mysql_master"
+	DependencyMysqlSlave  = "mysql_slave_%v" // addr
+	DependencyRedis       = "redis_cluster"
+	DependencyMemcache    = "memcache"
+	DependencyEtcd        = "etcd"
+)
+
+var ErrNotReady = errors.New("critical dependency down")
+
+type dependency struct {
+	name     string
+	critical bool
+	probe    func(ctx context.Context) error
+}
+
+type DependencyStatus struct {
+	Name     string    `json:"name"`
+	Critical bool      `json:"critical"`
+	Up       bool      `json:"up"`
+	Error    string    `json:"error,omitempty"`
+	Since    time.Time `json:"since"`
+}
+
+type healthChecker struct {
+	deps        []dependency
+	interval    time.Duration
+	serviceName string
+	grpcHealth  *health.Server
+
+	mu       sync.RWMutex
+	status   []DependencyStatus
+	ready    bool
+	draining bool
+	lastRun  time.Time
+}
+
+var checker *healthChecker
+
+// initHealth probes every client created by InitService once, so readiness
+// is known before the service registers, then keeps probing in the
+// background.
+func initHealth(config *conf.Conf) {
+	interval := time.Duration(config.Health.Interval) * time.Second
+	if interval <= 0 {
+		interval = DefaultHealthInterval
+	}
+	checker = &healthChecker{
+		interval:    interval,
+		serviceName: config.Grpc.Name,
+		grpcHealth:  health.NewServer(),
+	}
+	checker.deps = append(checker.deps,
+		dependency{name: DependencyMysqlMaster, critical: true, probe: func(ctx context.Context) error {
+			return dbCli.DB().PingContext(ctx)
+		}},
+		dependency{name: DependencyRedis, critical: true, probe: func(ctx context.Context) error {
+			return redisCluster.ForEachShard(ctx, func(ctx context.Context, c *redis.Client) error {
+				return c.Ping(ctx).Err()
+			})
+		}},
+		// memcached only holds data when it serves the counters
+		dependency{name: DependencyMemcache, critical: countCache == CountCacheMemcache, probe: func(ctx context.Context) error {
+			return mcCli.Ping()
+		}},
+		// losing etcd only stops registry updates, the instance keeps serving
+		dependency{name: DependencyEtcd, probe: func(ctx context.Context) error {
+			return probeEtcd(ctx, config.Etcd.Addr)
+		}},
+	)
+	// reads fall back to the master, so a slave is never critical
+	for _, r := range slaves.replicas {
+		r := r
+		checker.deps = append(checker.deps, dependency{name: fmt.Sprintf(DependencyMysqlSlave, r.addr), probe: func(ctx context.Context) error {
+			return r.db.DB().PingContext(ctx)
+		}})
+	}
+	checker.check()
+	lifecycle.Run("health watch", checker.watch)
+}
+
+func (hc *healthChecker) watch(ctx context.Context) {
+	ticker := time.NewTicker(hc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		hc.check()
+	}
+}
+
+func (hc *healthChecker) check() {
+	results := make([]error, len(hc.deps))
+	wg := concurrent.NewWaitGroup()
+	for i, dep := range hc.deps {
+		i, dep := i, dep
+		wg.Run(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), HealthCheckTimeout)
+			defer cancel()
+			results[i] = dep.probe(ctx)
+		})
+	}
+	wg.Wait()
+
+	now := time.Now()
+	hc.mu.Lock()
+	prev := make(map[string]DependencyStatus, len(hc.status))
+	for _, s := range hc.status {
+		prev[s.Name] = s
+	}
+	status := make([]DependencyStatus, 0, len(hc.deps))
+	ready := true
+	for i, dep := range hc.deps {
+		s := DependencyStatus{Name: dep.name, Critical: dep.critical, Up: results[i] == nil, Since: now}
+		if results[i] != nil {
+			s.Error = results[i].Error()
+			if dep.critical {
+				ready = false
+			}
+		}
+		if p, ok := prev[dep.name]; ok && p.Up == s.Up {
+			s.Since = p.Since
+		} else if ok {
+			logger.Warn(context.Background(), "dependency health changed", zap.String("dependency", dep.name), zap.Bool("up", s.Up), zap.String("error", s.Error))
+		}
+		status = append(status, s)
+	}
+	wasReady := hc.ready || hc.lastRun.IsZero()
+	hc.status = status
+	hc.ready = ready
+	hc.lastRun = now
+	hc.mu.Unlock()
+
+	servingStatus := grpc_health_v1.HealthCheckResponse_SERVING
+	if !ready {
+		servingStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	}
+	hc.grpcHealth.SetServingStatus("", servingStatus)
+	hc.grpcHealth.SetServingStatus(hc.serviceName, servingStatus)
+	if wasReady != ready {
+		logger.Warn(context.Background(), "readiness changed", zap.Bool("ready", ready))
+	}
+}
+
+func (hc *healthChecker) snapshot() ([]DependencyStatus, bool, time.Time) {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.status, hc.ready && !hc.draining, hc.lastRun
+}
+
+// probeEtcd asks each endpoint's /health until one answers healthy.
+func probeEtcd(ctx context.Context, addrs []string) error {
+	var err error
+	for _, addr := range addrs {
+		if !strings.Contains(addr, "://") {
+			addr = "http://" + addr
+		}
+		err = getEtcdHealth(ctx, addr+"/health")
+		if err == nil {
+			return nil
+		}
+	}
+	if err == nil {
+		err = errors.New("no etcd endpoints")
+	}
+	return err
+}
+
+func getEtcdHealth(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	var res struct {
+		Health string `json:"health"`
+	}
+	if err = json.Unmarshal(body, &res); err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK || res.Health != "true" {
+		return fmt.Errorf("etcd %v unhealthy: %s", url, body)
+	}
+	return nil
+}
+
+// Healthz is the liveness probe. It fails only when the probe loop itself
+// has stopped making progress; dependency outages are left to Readyz.
+func Healthz(w http.ResponseWriter, r *http.Request) {
+	_, _, lastRun := checker.snapshot()
+	if time.Since(lastRun) > HealthStaleRounds*checker.interval+HealthCheckTimeout {
+		http.Error(w, "health checks stalled", http.StatusServiceUnavailable)
+		return
+	}
+	_, _ = w.Write([]byte("ok"))
+}
+
+// Readyz is the readiness probe. It answers 503 while any critical
+// dependency is down and lists the state of every dependency either way.
+func Readyz(w http.ResponseWriter, r *http.Request) {
+	status, ready, _ := checker.snapshot()
+	code := http.StatusOK
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(struct {
+		Ready        bool               `json:"ready"`
+		Dependencies []DependencyStatus `json:"dependencies"`
+	}{ready, status})
+}
+
+// ReadyCheck is the registry's register check: go-micro deregisters the
+// instance when it fails. It does not keep a deregistered instance out,
+// which is what ReadyRegistry is for.
+func ReadyCheck(ctx context.Context) error {
+	_, ready, _ := checker.snapshot()
+	if !ready {
+		return ErrNotReady
+	}
+	return nil
+}
+
+// ReadyRegistry wraps r so that registering fails while the instance is
+// not ready. Once a failed check has deregistered it, go-micro calls
+// Register on every interval whatever the check says, which would put it
+// straight back.
+func ReadyRegistry(r registry.Registry) registry.Registry {
+	return readyRegistry{Registry: r}
+}
+
+type readyRegistry struct {
+	registry.Registry
+}
+
+func (r readyRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	err := ReadyCheck(context.Background())
+	if err != nil {
+		return err
+	}
+	return r.Registry.Register(s, opts...)
+}
+
+// Drain fails readiness for good, so the registry and load balancers stop
+// routing here while the server finishes what it has.
+func Drain() {
+	checker.mu.Lock()
+	checker.draining = true
+	checker.mu.Unlock()
+	checker.grpcHealth.Shutdown()
+}
+
+func HealthInterval() time.Duration {
+	return checker.interval
+}
+
+// ServeHealthGRPC serves grpc.health.v1 on addr until shutdown.
+func ServeHealthGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, checker.grpcHealth)
+	lifecycle.OnStop("grpc health", func(ctx context.Context) error {
+		s.Stop()
+		return nil
+	})
+	return s.Serve(lis)
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"github.com/micro/go-micro/broker/memory"
+	"github.com/micro/go-micro/registry"
+	mregistry "github.com/micro/go-micro/registry/memory"
+	mserver "github.com/micro/go-micro/server"
+	mtransport "github.com/micro/go-micro/transport/memory"
+	"google.golang.org/grpc/health"
+	"sync"
+	"testing"
+	"time"
+)
+
+// nodeRegistry tracks which nodes are registered. The memory registry
+// does not put a node back once its service has been seen, so it cannot
+// show a deregistered instance registering again.
+type nodeRegistry struct {
+	registry.Registry
+	mu    sync.Mutex
+	nodes map[string]bool
+	added int
+}
+
+func newNodeRegistry() *nodeRegistry {
+	return &nodeRegistry{Registry: mregistry.NewRegistry(), nodes: map[string]bool{}}
+}
+
+func (r *nodeRegistry) Register(s *registry.Service, opts ...registry.RegisterOption) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range s.Nodes {
+		if !r.nodes[n.Id] {
+			r.nodes[n.Id] = true
+			r.added++
+		}
+	}
+	return nil
+}
+
+func (r *nodeRegistry) Deregister(s *registry.Service) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range s.Nodes {
+		delete(r.nodes, n.Id)
+	}
+	return nil
+}
+
+func (r *nodeRegistry) registered() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.nodes) > 0
+}
+
+// registrations counts the times a node went from absent to registered.
+func (r *nodeRegistry) registrations() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.added
+}
+
+func setReady(ready bool) {
+	checker.mu.Lock()
+	checker.ready = ready
+	checker.mu.Unlock()
+}
+
+// TestReadyRegistry runs go-micro's register loop against an instance
+// that turns unready and checks it stays out of the registry until it is
+// ready again.
+func TestReadyRegistry(t *testing.T) {
+	checker = &healthChecker{grpcHealth: health.NewServer(), ready: true, lastRun: time.Now()}
+	reg := newNodeRegistry()
+	srv := mserver.NewServer(
+		mserver.Name("social.test"),
+		mserver.Address("127.0.0.1:0"),
+		mserver.Registry(ReadyRegistry(reg)),
+		mserver.Broker(memory.NewBroker()),
+		mserver.Transport(mtransport.NewTransport()),
+		mserver.RegisterCheck(ReadyCheck),
+		mserver.RegisterInterval(10*time.Millisecond),
+	)
+	if err := srv.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Stop()
+
+	registered := reg.registered
+	eventually(t, "the first registration", registered)
+
+	setReady(false)
+	eventually(t, "the deregistration", func() bool {
+		return !registered()
+	})
+	// the loop keeps ticking; a register that ignores readiness would put
+	// the instance back within a couple of intervals
+	before := reg.registrations()
+	time.Sleep(100 * time.Millisecond)
+	if n := reg.registrations() - before; n > 0 {
+		t.Fatalf("unready instance was registered %v more times", n)
+	}
+
+	setReady(true)
+	eventually(t, "the registration once ready", registered)
+}
+
+func TestReadyRegistryDrain(t *testing.T) {
+	checker = &healthChecker{grpcHealth: health.NewServer(), ready: true, lastRun: time.Now()}
+	reg := ReadyRegistry(newNodeRegistry())
+	svc := &registry.Service{Name: "social.test", Nodes: []*registry.Node{{Id: "1", Address: "127.0.0.1:1"}}}
+	if err := reg.Register(svc); err != nil {
+		t.Fatal(err)
+	}
+	Drain()
+	if err := reg.Register(svc); err != ErrNotReady {
+		t.Errorf("Register while draining = %v, want ErrNotReady", err)
+	}
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"socialservice/util/concurrent"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"time"
+)
+
+const (
+	HistoryDay   = "day"
+	HistoryWeek  = "week"
+	HistoryMonth = "month"
+
+	// HistorySnapshotClaim is how long an instance holds a day it has
+	// started recording.
+	HistorySnapshotClaim = 48 * time.Hour
+)
+
+// historyMaxDays bounds the range of one GetFollowerHistory call: about a
+// year of days, five of weeks and ten of months.
+var historyMaxDays = map[string]int{
+	HistoryDay:   366,
+	HistoryWeek:  5 * 366,
+	HistoryMonth: 10 * 366,
+}
+
+// periodStart is the first day of the period t falls in.
+func periodStart(granularity string, t time.Time) time.Time {
+	day := statDay(t)
+	switch granularity {
+	case HistoryWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case HistoryMonth:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+func nextPeriod(granularity string, start time.Time) time.Time {
+	switch granularity {
+	case HistoryWeek:
+		return start.AddDate(0, 0, 7)
+	case HistoryMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// getFollowerHistory returns the follower count of uid for each period
+// from the one holding from to the one holding to. Snapshots are only
+// taken on days the followers changed, so a period without one carries
+// the count before it; periods before the first snapshot are left out.
+// The current period ends with the live counter.
+func getFollowerHistory(ctx context.Context, uid int64, granularity string, from, to time.Time) ([]FollowerHistory, error) {
+	from, to = periodStart(granularity, from), periodStart(granularity, to)
+	// a range reaching past the last snapshot changes with the next one, so
+	// cached ranges are keyed on the snapshot version they were read at
+	var (
+		stored []FollowerHistory
+		ok     bool
+	)
+	version, vErr := cacheGetHistoryVersion(ctx)
+	if vErr == nil {
+		stored, ok, _ = cacheGetFollowerHistory(ctx, uid, granularity, from, to, version)
+	}
+	if !ok {
+		var err error
+		stored, err = dbGetFollowerHistory(ctx, uid, granularity, from, to)
+		if err != nil {
+			return nil, err
+		}
+		// without the version there is no key the next snapshot retires
+		if vErr == nil {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollowerHistory(ctx, uid, granularity, from, to, version, stored)
+			})
+		}
+	}
+	var (
+		points []FollowerHistory
+		count  int64
+		known  bool
+		i      int
+	)
+	for p := from; !p.After(to); p = nextPeriod(granularity, p) {
+		for ; i < len(stored) && !stored[i].Period.After(p); i++ {
+			count, known = stored[i].FollowerCount, true
+		}
+		if known {
+			points = append(points, FollowerHistory{UID: uid, Granularity: granularity, Period: p, FollowerCount: count})
+		}
+	}
+	current := periodStart(granularity, time.Now())
+	if current.Before(from) || current.After(to) {
+		return points, nil
+	}
+	_, followerCnt, err := getFollowCount(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(points); n > 0 && points[n-1].Period.Equal(current) {
+		points[n-1].FollowerCount = followerCnt
+	} else {
+		points = append(points, FollowerHistory{UID: uid, Granularity: granularity, Period: current, FollowerCount: followerCnt})
+	}
+	return points, nil
+}
+
+// SnapshotFollowerHistory records the follower count of every user whose
+// followers changed on day, as that day's point and as the latest of its
+// week and month, and returns how many users it recorded. The counters
+// are read when it runs, so run it soon after the day ends. Histories
+// cached before it are not served after it.
+func SnapshotFollowerHistory(ctx context.Context, day time.Time) (int, error) {
+	n, err := snapshotFollowerHistory(ctx, day)
+	if n > 0 {
+		cacheBumpHistoryVersion(ctx)
+	}
+	return n, err
+}
+
+func snapshotFollowerHistory(ctx context.Context, day time.Time) (int, error) {
+	day = statDay(day)
+	week, month := periodStart(HistoryWeek, day), periodStart(HistoryMonth, day)
+	var (
+		afterUID int64
+		n        int
+	)
+	for {
+		t := settings()
+		uids, err := dbGetStatUIDs(ctx, day, afterUID, t.batchSize)
+		if err != nil || len(uids) == 0 {
+			return n, err
+		}
+		counts, err := dbGetFollowerCounts(ctx, uids)
+		if err != nil {
+			return n, err
+		}
+		points := make([]FollowerHistory, 0, 3*len(uids))
+		for _, uid := range uids {
+			for _, p := range []struct {
+				granularity string
+				period      time.Time
+			}{{HistoryDay, day}, {HistoryWeek, week}, {HistoryMonth, month}} {
+				points = append(points, FollowerHistory{UID: uid, Granularity: p.granularity, Period: p.period, FollowerCount: counts[uid], AsOf: day})
+			}
+		}
+		err = dbSaveFollowerHistory(ctx, points)
+		if err != nil {
+			return n, err
+		}
+		n += len(uids)
+		afterUID = uids[len(uids)-1]
+		if len(uids) < t.batchSize {
+			return n, nil
+		}
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		case <-time.After(t.batchSleep):
+		}
+	}
+}
+
+// startHistorySnapshot records the day before every day at at, HH:MM UTC,
+// until shutdown. Every instance schedules it; the first to claim the day
+// in Redis records it.
+func startHistorySnapshot(at string) error {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return fmt.Errorf("bad history.snapshot_at %q: %v", at, err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	lifecycle.OnStop("history snapshot", func(context.Context) error {
+		cancel()
+		return nil
+	})
+	concurrent.Go(func() {
+		for {
+			now := time.Now().UTC()
+			next := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, time.UTC)
+			if !next.After(now) {
+				next = next.AddDate(0, 0, 1)
+			}
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			runHistorySnapshot(ctx, statDay(next).AddDate(0, 0, -1))
+		}
+	})
+	return nil
+}
+
+func runHistorySnapshot(ctx context.Context, day time.Time) {
+	key := fmt.Sprintf(RedisKeyHistorySnapshot, day.Format(statDayLayout))
+	claimed, err := redisCli.SetNX(ctx, key, 1, HistorySnapshotClaim).Result()
+	if err != nil {
+		logger.Error(ctx, "claim history snapshot", zap.String("day", day.Format(statDayLayout)), logger.Err(err))
+		return
+	}
+	if !claimed {
+		return
+	}
+	start := time.Now()
+	n, err := SnapshotFollowerHistory(ctx, day)
+	if err != nil {
+		// the day stays claimed; socialctl snapshot records it again
+		logger.Error(ctx, "history snapshot", zap.String("day", day.Format(statDayLayout)), zap.Int("users", n), logger.Err(err))
+		return
+	}
+	logger.Info(ctx, "history snapshot", zap.String("day", day.Format(statDayLayout)), zap.Int("users", n), zap.Duration("took", time.Since(start)))
+}
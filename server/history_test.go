@@ -0,0 +1,82 @@
+package server
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+	"time"
+)
+
+func expectHistory(env *testEnv, points ...FollowerHistory) {
+	env.sql.ExpectQuery("FROM `follower_history` WHERE .*period < ").
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "granularity", "period", "follower_count", "as_of"}))
+	rows := sqlmock.NewRows([]string{"uid", "granularity", "period", "follower_count", "as_of"})
+	for _, p := range points {
+		rows.AddRow(p.UID, p.Granularity, p.Period, p.FollowerCount, p.Period)
+	}
+	env.sql.ExpectQuery("FROM `follower_history` WHERE .*period BETWEEN ").WillReturnRows(rows)
+}
+
+func historyCounts(t *testing.T, from, to time.Time) []int64 {
+	t.Helper()
+	points, err := getFollowerHistory(testCtx, 1, HistoryDay, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := make([]int64, 0, len(points))
+	for _, p := range points {
+		counts = append(counts, p.FollowerCount)
+	}
+	return counts
+}
+
+// TestFollowerHistoryCacheFollowsSnapshots checks a cached range is served
+// until the next snapshot and read again after it.
+func TestFollowerHistoryCacheFollowsSnapshots(t *testing.T) {
+	env := newTestEnv(t)
+	day1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	day3 := day1.AddDate(0, 0, 2)
+	expectHistory(env, FollowerHistory{UID: 1, Granularity: HistoryDay, Period: day1, FollowerCount: 5})
+
+	if got := historyCounts(t, day1, day3); len(got) != 3 || got[2] != 5 {
+		t.Fatalf("history = %v, want 5 carried over three days", got)
+	}
+	eventually(t, "the backfill", func() bool {
+		return len(env.redis.Keys()) > 0
+	})
+	// cached: sqlmock fails any query it does not expect
+	if got := historyCounts(t, day1, day3); len(got) != 3 || got[2] != 5 {
+		t.Fatalf("cached history = %v", got)
+	}
+
+	// a snapshot of day3 lands; the range must be read again
+	cacheBumpHistoryVersion(testCtx)
+	expectHistory(env,
+		FollowerHistory{UID: 1, Granularity: HistoryDay, Period: day1, FollowerCount: 5},
+		FollowerHistory{UID: 1, Granularity: HistoryDay, Period: day3, FollowerCount: 7},
+	)
+	if got := historyCounts(t, day1, day3); len(got) != 3 || got[1] != 5 || got[2] != 7 {
+		t.Errorf("history after a snapshot = %v, want [5 5 7]", got)
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestFollowerHistoryWithoutRedis(t *testing.T) {
+	env := newTestEnv(t)
+	day1 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expectHistory(env, FollowerHistory{UID: 1, Granularity: HistoryDay, Period: day1, FollowerCount: 5})
+	env.redis.SetError("down")
+	if got := historyCounts(t, day1, day1.AddDate(0, 0, 1)); len(got) != 2 || got[1] != 5 {
+		t.Errorf("history read from MySQL = %v, want [5 5]", got)
+	}
+	env.redis.SetError("")
+	// wait out a backfill that should not have been queued
+	time.Sleep(20 * time.Millisecond)
+	if keys := env.redis.Keys(); len(keys) != 0 {
+		t.Errorf("cached %v without a snapshot version", keys)
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
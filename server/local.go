@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"go.uber.org/zap"
+	"socialservice/conf"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"socialservice/util/lru"
+	"socialservice/util/metrics"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLocalCacheSize = 100000
+	DefaultLocalCacheTTL  = 3 * time.Second
+	LocalCacheStatsPeriod = time.Minute
+
+	RedisChannelLocalInvalidate = "social_service_local_invalidate" // comma separated keys
+	LocalKeyFollowCount         = "follow_count_%v"                 // uid
+)
+
+type localCount struct {
+	followCount   int64
+	followerCount int64
+}
+
+type localPage struct {
+	offset  int64
+	uids    []int64
+	hasMore bool
+}
+
+var localCache *lru.Cache
+
+func initLocalCache(config conf.LocalCacheConf) {
+	size := config.Size
+	if size <= 0 {
+		size = DefaultLocalCacheSize
+	}
+	ttl := time.Duration(config.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = DefaultLocalCacheTTL
+	}
+	localCache = lru.New(size, ttl)
+	metrics.RegisterLRU("local", localCache)
+	lifecycle.Run("local cache invalidation", subscribeLocalInvalidate)
+	lifecycle.Run("local cache stats", reportLocalCacheStats)
+}
+
+// localInvalidate drops keys from this instance and tells the others to do
+// the same.
+func localInvalidate(ctx context.Context, keys ...string) {
+	localCache.Remove(keys...)
+	err := redisCli.Publish(ctx, RedisChannelLocalInvalidate, strings.Join(keys, ",")).Err()
+	if err != nil {
+		logger.Error(ctx, "publish local invalidate", zap.Strings("keys", keys), logger.Err(err))
+	}
+}
+
+func subscribeLocalInvalidate(ctx context.Context) {
+	sub := redisCluster.Subscribe(ctx, RedisChannelLocalInvalidate)
+	defer sub.Close()
+	msgs := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-msgs:
+			if !ok {
+				return
+			}
+			localCache.Remove(strings.Split(msg.Payload, ",")...)
+		}
+	}
+}
+
+func reportLocalCacheStats(ctx context.Context) {
+	ticker := time.NewTicker(LocalCacheStatsPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		stats := localCache.Stats()
+		var hitRate float64
+		if total := stats.Hits + stats.Misses; total > 0 {
+			hitRate = float64(stats.Hits) / float64(total)
+		}
+		logger.Info(context.Background(), "local cache stats", zap.Int("len", stats.Len), zap.Uint64("hits", stats.Hits), zap.Uint64("misses", stats.Misses), zap.Uint64("evictions", stats.Evictions), zap.Float64("hit_rate", hitRate))
+	}
+}
+
+func localCountKey(uid int64) string {
+	return fmt.Sprintf(LocalKeyFollowCount, uid)
+}
+
+func localGetFollowCount(uid int64) (int64, int64, bool) {
+	val, ok := localCache.Get(localCountKey(uid))
+	if !ok {
+		return 0, 0, false
+	}
+	cnt := val.(localCount)
+	return cnt.followCount, cnt.followerCount, true
+}
+
+func localSetFollowCount(uid, followCount, followerCount int64) {
+	localCache.Set(localCountKey(uid), localCount{followCount: followCount, followerCount: followerCount})
+}
+
+func localGetFollow(key string, offset int64) ([]int64, bool, bool) {
+	val, ok := localCache.Get(key)
+	if !ok {
+		return nil, false, false
+	}
+	page := val.(localPage)
+	if page.offset != offset {
+		return nil, false, false
+	}
+	return page.uids, page.hasMore, true
+}
+
+func localSetFollow(key string, offset int64, uids []int64, hasMore bool) {
+	localCache.Set(key, localPage{offset: offset, uids: uids, hasMore: hasMore})
+}
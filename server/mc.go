@@ -0,0 +1,157 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/bradfitz/gomemcache/memcache"
+	"go.uber.org/zap"
+	"socialservice/util/cast"
+	"socialservice/util/logger"
+	"socialservice/util/metrics"
+	"strings"
+)
+
+const (
+	CountCacheRedis    = "redis"
+	CountCacheMemcache = "memcache"
+
+	MCCountTTL   = 5 * 60 // seconds
+	MCCasRetries = 3
+
+	MCKeyFollowCount      = "social_service_follow_count_%v"       // uid follow_count,follower_count
+	MCKeyFollowTopicCount = "social_service_follow_topic_count_%v" // uid follow_count
+)
+
+var countCache = CountCacheRedis
+
+func mcGetFollowCount(ctx context.Context, uid int64) (int64, int64, error) {
+	key := fmt.Sprintf(MCKeyFollowCount, uid)
+	if followCount, followerCount, ok := localGetFollowCount(uid); ok {
+		metrics.CacheHit("local_follow_count")
+		return followCount, followerCount, nil
+	}
+	metrics.CacheMiss("local_follow_count")
+	item, err := mcCli.Get(key)
+	if err != nil {
+		if err == memcache.ErrCacheMiss {
+			metrics.CacheMiss("mc_follow_count")
+		} else {
+			logger.Error(ctx, "mcGetFollowCount", logger.UID(uid), logger.Err(err))
+		}
+		return 0, 0, err
+	}
+	metrics.CacheHit("mc_follow_count")
+	cnt := mcDecode(item.Value, 2)
+	localSetFollowCount(uid, cnt[0], cnt[1])
+	return cnt[0], cnt[1], nil
+}
+
+// mcSetFollowCount backfills the counters of uid. It uses Add, so counters
+// an mcIncr has moved since they were read from MySQL are left alone
+// instead of being overwritten with the older values.
+func mcSetFollowCount(ctx context.Context, uid, followCount, followerCount int64) {
+	key := fmt.Sprintf(MCKeyFollowCount, uid)
+	err := mcCli.Add(&memcache.Item{Key: key, Value: mcEncode(followCount, followerCount), Expiration: MCCountTTL})
+	if err != nil && err != memcache.ErrNotStored {
+		logger.Error(ctx, "mcSetFollowCount", logger.UID(uid), logger.Err(err))
+	}
+}
+
+func mcGetFollowTopicCount(ctx context.Context, uid int64) (int64, error) {
+	key := fmt.Sprintf(MCKeyFollowTopicCount, uid)
+	item, err := mcCli.Get(key)
+	if err != nil {
+		if err == memcache.ErrCacheMiss {
+			metrics.CacheMiss("mc_follow_topic_count")
+		} else {
+			logger.Error(ctx, "mcGetFollowTopicCount", logger.UID(uid), logger.Err(err))
+		}
+		return 0, err
+	}
+	metrics.CacheHit("mc_follow_topic_count")
+	return mcDecode(item.Value, 1)[0], nil
+}
+
+func mcSetFollowTopicCount(ctx context.Context, uid, topicCnt int64) {
+	key := fmt.Sprintf(MCKeyFollowTopicCount, uid)
+	err := mcCli.Add(&memcache.Item{Key: key, Value: mcEncode(topicCnt), Expiration: MCCountTTL})
+	if err != nil && err != memcache.ErrNotStored {
+		logger.Error(ctx, "mcSetFollowTopicCount", logger.UID(uid), logger.Err(err))
+	}
+}
+
+func mcFollow(ctx context.Context, uid, toUID int64) {
+	mcIncr(ctx, fmt.Sprintf(MCKeyFollowCount, uid), 1, 0)
+	mcIncr(ctx, fmt.Sprintf(MCKeyFollowCount, toUID), 0, 1)
+}
+
+func mcUnfollow(ctx context.Context, uid, toUID int64) {
+	mcIncr(ctx, fmt.Sprintf(MCKeyFollowCount, uid), -1, 0)
+	mcIncr(ctx, fmt.Sprintf(MCKeyFollowCount, toUID), 0, -1)
+}
+
+func mcFollowTopic(ctx context.Context, uid int64, delta int64) {
+	mcIncr(ctx, fmt.Sprintf(MCKeyFollowTopicCount, uid), delta)
+}
+
+// mcIncr adds deltas to the counters stored under key with a CAS loop. A
+// missing key is left alone for the next read to load; a key that keeps
+// conflicting is dropped so it cannot drift.
+func mcIncr(ctx context.Context, key string, deltas ...int64) {
+	for i := 0; i < MCCasRetries; i++ {
+		item, err := mcCli.Get(key)
+		if err == memcache.ErrCacheMiss {
+			return
+		}
+		if err != nil {
+			logger.Error(ctx, "mcIncr get", zap.String("key", key), logger.Err(err))
+			break
+		}
+		cnt := mcDecode(item.Value, len(deltas))
+		for j, d := range deltas {
+			cnt[j] += d
+			if cnt[j] < 0 {
+				cnt[j] = 0
+			}
+		}
+		item.Value = mcEncode(cnt...)
+		item.Expiration = MCCountTTL
+		err = mcCli.CompareAndSwap(item)
+		if err == nil || err == memcache.ErrNotStored {
+			return
+		}
+		if err != memcache.ErrCASConflict {
+			logger.Error(ctx, "mcIncr cas", zap.String("key", key), logger.Err(err))
+			break
+		}
+	}
+	err := mcCli.Delete(key)
+	if err != nil && err != memcache.ErrCacheMiss {
+		logger.Error(ctx, "mcIncr delete", zap.String("key", key), logger.Err(err))
+	}
+}
+
+func mcDrop(ctx context.Context, uid int64) {
+	for _, key := range []string{fmt.Sprintf(MCKeyFollowCount, uid), fmt.Sprintf(MCKeyFollowTopicCount, uid)} {
+		err := mcCli.Delete(key)
+		if err != nil && err != memcache.ErrCacheMiss {
+			logger.Error(ctx, "mcDrop", zap.String("key", key), logger.Err(err))
+		}
+	}
+}
+
+func mcEncode(vals ...int64) []byte {
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		strs = append(strs, cast.FormatInt(v))
+	}
+	return []byte(strings.Join(strs, ","))
+}
+
+func mcDecode(b []byte, n int) []int64 {
+	vals := make([]int64, n)
+	for i, v := range strings.SplitN(string(b), ",", n) {
+		vals[i] = cast.ParseInt(v, 0)
+	}
+	return vals
+}
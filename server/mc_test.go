@@ -0,0 +1,261 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/bradfitz/gomemcache/memcache"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeItem struct {
+	value []byte
+	cas   uint64
+}
+
+// fakeMemcached speaks the part of the memcached text protocol gomemcache
+// uses for gets, set, add, cas and delete. beforeCAS, when set, runs
+// before every cas is applied, so a test can change the item in between.
+type fakeMemcached struct {
+	addr      string
+	mu        sync.Mutex
+	items     map[string]fakeItem
+	nextCAS   uint64
+	beforeCAS func()
+}
+
+func newFakeMemcached(t *testing.T) *fakeMemcached {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+	m := &fakeMemcached{addr: l.Addr().String(), items: map[string]fakeItem{}}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(c)
+		}
+	}()
+	return m
+}
+
+func (m *fakeMemcached) client() *memcache.Client {
+	return memcache.New(m.addr)
+}
+
+func (m *fakeMemcached) get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	it, ok := m.items[key]
+	return string(it.value), ok
+}
+
+func (m *fakeMemcached) set(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextCAS++
+	m.items[key] = fakeItem{value: []byte(value), cas: m.nextCAS}
+}
+
+func (m *fakeMemcached) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		f := strings.Fields(line)
+		if len(f) == 0 {
+			continue
+		}
+		var reply string
+		switch f[0] {
+		case "gets":
+			var b strings.Builder
+			m.mu.Lock()
+			for _, key := range f[1:] {
+				if it, ok := m.items[key]; ok {
+					fmt.Fprintf(&b, "VALUE %s 0 %d %d\r\n%s\r\n", key, len(it.value), it.cas, it.value)
+				}
+			}
+			m.mu.Unlock()
+			reply = b.String() + "END\r\n"
+		case "set", "add", "cas":
+			size, _ := strconv.Atoi(f[4])
+			data := make([]byte, size+2)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return
+			}
+			var cas uint64
+			if f[0] == "cas" {
+				cas, _ = strconv.ParseUint(f[5], 10, 64)
+				if m.beforeCAS != nil {
+					m.beforeCAS()
+				}
+			}
+			reply = m.store(f[0], f[1], data[:size], cas)
+		case "delete":
+			m.mu.Lock()
+			_, ok := m.items[f[1]]
+			delete(m.items, f[1])
+			m.mu.Unlock()
+			reply = "NOT_FOUND\r\n"
+			if ok {
+				reply = "DELETED\r\n"
+			}
+		default:
+			reply = "ERROR\r\n"
+		}
+		if _, err := io.WriteString(c, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (m *fakeMemcached) store(cmd, key string, value []byte, cas uint64) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	it, ok := m.items[key]
+	switch {
+	case cmd == "add" && ok:
+		return "NOT_STORED\r\n"
+	case cmd == "cas" && !ok:
+		return "NOT_FOUND\r\n"
+	case cmd == "cas" && it.cas != cas:
+		return "EXISTS\r\n"
+	}
+	m.nextCAS++
+	m.items[key] = fakeItem{value: value, cas: m.nextCAS}
+	return "STORED\r\n"
+}
+
+func TestMCFollowCounts(t *testing.T) {
+	env := newTestEnv(t)
+	env.mc.set(fmt.Sprintf(MCKeyFollowCount, 1), "3,4")
+	env.mc.set(fmt.Sprintf(MCKeyFollowCount, 2), "7,0")
+
+	mcFollow(testCtx, 1, 2)
+	if v, _ := env.mc.get(fmt.Sprintf(MCKeyFollowCount, 1)); v != "4,4" {
+		t.Errorf("follower side after follow = %q, want 4,4", v)
+	}
+	if v, _ := env.mc.get(fmt.Sprintf(MCKeyFollowCount, 2)); v != "7,1" {
+		t.Errorf("followed side after follow = %q, want 7,1", v)
+	}
+
+	mcUnfollow(testCtx, 1, 2)
+	mcUnfollow(testCtx, 1, 2)
+	if v, _ := env.mc.get(fmt.Sprintf(MCKeyFollowCount, 1)); v != "2,4" {
+		t.Errorf("follower side after unfollows = %q, want 2,4", v)
+	}
+	if v, _ := env.mc.get(fmt.Sprintf(MCKeyFollowCount, 2)); v != "7,0" {
+		t.Errorf("followed side after unfollows = %q, want 7,0, never below zero", v)
+	}
+}
+
+func TestMCIncrLeavesMissingKey(t *testing.T) {
+	env := newTestEnv(t)
+	mcFollowTopic(testCtx, 1, 1)
+	if v, ok := env.mc.get(fmt.Sprintf(MCKeyFollowTopicCount, 1)); ok {
+		t.Errorf("missing counter was created as %q", v)
+	}
+}
+
+func TestMCIncrRetriesCASConflict(t *testing.T) {
+	env := newTestEnv(t)
+	key := fmt.Sprintf(MCKeyFollowTopicCount, 1)
+	env.mc.set(key, "5")
+	conflicts := 1
+	env.mc.beforeCAS = func() {
+		if conflicts > 0 {
+			conflicts--
+			env.mc.set(key, "10")
+		}
+	}
+	mcFollowTopic(testCtx, 1, 1)
+	if v, _ := env.mc.get(key); v != "11" {
+		t.Errorf("counter = %q, want 11: the retry must apply the delta to the concurrent value", v)
+	}
+}
+
+func TestMCIncrDropsKeyThatKeepsConflicting(t *testing.T) {
+	env := newTestEnv(t)
+	key := fmt.Sprintf(MCKeyFollowTopicCount, 1)
+	env.mc.set(key, "5")
+	env.mc.beforeCAS = func() {
+		env.mc.set(key, "5")
+	}
+	mcFollowTopic(testCtx, 1, 1)
+	if v, ok := env.mc.get(key); ok {
+		t.Errorf("counter = %q, want it dropped after %v conflicts", v, MCCasRetries)
+	}
+}
+
+func TestGetFollowCountBackfillsMemcache(t *testing.T) {
+	env := newTestEnv(t)
+	countCache = CountCacheMemcache
+	env.sql.ExpectQuery("SELECT follow_count, follower_count FROM `follow_count`").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"follow_count", "follower_count"}).AddRow(10, 20))
+
+	follows, followers, err := getFollowCount(testCtx, 1)
+	if err != nil || follows != 10 || followers != 20 {
+		t.Fatalf("getFollowCount = %v, %v, %v, want 10, 20 from MySQL", follows, followers, err)
+	}
+	key := fmt.Sprintf(MCKeyFollowCount, 1)
+	eventually(t, "the backfill", func() bool {
+		v, _ := env.mc.get(key)
+		return v == "10,20"
+	})
+
+	// the next read is served from memcached; sqlmock fails any query
+	localCache.Remove(localCountKey(1))
+	follows, followers, err = getFollowCount(testCtx, 1)
+	if err != nil || follows != 10 || followers != 20 {
+		t.Fatalf("second getFollowCount = %v, %v, %v, want 10, 20 from memcached", follows, followers, err)
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMCBackfillKeepsLiveCounter(t *testing.T) {
+	env := newTestEnv(t)
+	key := fmt.Sprintf(MCKeyFollowCount, 1)
+	// a follow moved the counter after the backfill read MySQL
+	env.mc.set(key, "11,20")
+	mcSetFollowCount(testCtx, 1, 10, 20)
+	if v, _ := env.mc.get(key); v != "11,20" {
+		t.Errorf("counter = %q, the backfill overwrote the live 11,20", v)
+	}
+}
+
+func TestMCDrop(t *testing.T) {
+	env := newTestEnv(t)
+	env.mc.set(fmt.Sprintf(MCKeyFollowCount, 1), "1,2")
+	env.mc.set(fmt.Sprintf(MCKeyFollowTopicCount, 1), "3")
+	env.mc.set(fmt.Sprintf(MCKeyFollowCount, 2), "4,5")
+	mcDrop(testCtx, 1)
+	for _, key := range []string{fmt.Sprintf(MCKeyFollowCount, 1), fmt.Sprintf(MCKeyFollowTopicCount, 1)} {
+		if v, ok := env.mc.get(key); ok {
+			t.Errorf("%v = %q after mcDrop", key, v)
+		}
+	}
+	if _, ok := env.mc.get(fmt.Sprintf(MCKeyFollowCount, 2)); !ok {
+		t.Error("mcDrop removed another user's counter")
+	}
+	// dropping what is not cached is fine
+	mcDrop(testCtx, 1)
+}
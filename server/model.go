@@ -2,18 +2,37 @@ package server
 
 import "time"
 
+// Follow and Follower both carry the settings of the edge: Follow for the
+// follower reading them back, Follower for the push fan-out, which pages
+// through the followers of one uid. Source and Client record where the
+// follow was made, e.g. search on ios.
+//
+// Follow, Follower and FollowTopic are soft-deleted: gorm sets DeletedAt
+// instead of deleting and leaves such rows out of every query it builds
+// for these models, so only hand-written joins and Unscoped queries need
+// to mind it.
 type Follow struct {
-	UID       int64     `json:"uid"`
-	FollowUID int64     `json:"follow_uid"`
-	Ctime     time.Time `json:"ctime"`
-	Mtime     time.Time `json:"mtime"`
+	UID       int64      `json:"uid"`
+	FollowUID int64      `json:"follow_uid"`
+	Notify    int32      `json:"notify"`
+	Special   bool       `json:"special"`
+	Source    string     `json:"source"`
+	Client    string     `json:"client"`
+	Ctime     time.Time  `json:"ctime"`
+	Mtime     time.Time  `json:"mtime"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type Follower struct {
-	UID         int64     `json:"uid"`
-	FollowerUID int64     `json:"follower_uid"`
-	Ctime       time.Time `json:"ctime"`
-	Mtime       time.Time `json:"mtime"`
+	UID         int64      `json:"uid"`
+	FollowerUID int64      `json:"follower_uid"`
+	Notify      int32      `json:"notify"`
+	Special     bool       `json:"special"`
+	Source      string     `json:"source"`
+	Client      string     `json:"client"`
+	Ctime       time.Time  `json:"ctime"`
+	Mtime       time.Time  `json:"mtime"`
+	DeletedAt   *time.Time `json:"deleted_at"`
 }
 
 type FollowCount struct {
@@ -23,10 +42,11 @@ type FollowCount struct {
 }
 
 type FollowTopic struct {
-	UID     int64     `json:"uid"`
-	TopicID int64     `json:"topic_id"`
-	Ctime   time.Time `json:"ctime"`
-	Mtime   time.Time `json:"mtime"`
+	UID       int64      `json:"uid"`
+	TopicID   int64      `json:"topic_id"`
+	Ctime     time.Time  `json:"ctime"`
+	Mtime     time.Time  `json:"mtime"`
+	DeletedAt *time.Time `json:"deleted_at"`
 }
 
 type FollowTopicCount struct {
@@ -34,6 +54,67 @@ type FollowTopicCount struct {
 	FollowCount int64 `json:"follow_count"`
 }
 
+type FollowGroup struct {
+	ID    int64     `json:"id"` // snowflake
+	UID   int64     `json:"uid"`
+	Name  string    `json:"name"`
+	Ctime time.Time `json:"ctime"`
+	Mtime time.Time `json:"mtime"`
+}
+
+// FollowGroupMember keeps the owner's uid next to the group so unfollow
+// can find every group of uid holding a member.
+type FollowGroupMember struct {
+	GroupID   int64     `json:"group_id"`
+	UID       int64     `json:"uid"`
+	MemberUID int64     `json:"member_uid"`
+	Ctime     time.Time `json:"ctime"`
+	Mtime     time.Time `json:"mtime"`
+}
+
+// FollowStatDaily counts the followers uid gained and lost on Day, a UTC
+// date.
+type FollowStatDaily struct {
+	UID    int64     `json:"uid"`
+	Day    time.Time `json:"day"`
+	Gained int64     `json:"gained"`
+	Lost   int64     `json:"lost"`
+}
+
+// FollowerHistory is the follower count of UID on AsOf, the last day of
+// the period starting on Period that was recorded: a day, a week from
+// Monday or a month.
+type FollowerHistory struct {
+	UID           int64     `json:"uid"`
+	Granularity   string    `json:"granularity"` // day | week | month
+	Period        time.Time `json:"period"`
+	FollowerCount int64     `json:"follower_count"`
+	AsOf          time.Time `json:"as_of"`
+}
+
+// FollowAudit is one change of an edge, kept for abuse investigations.
+type FollowAudit struct {
+	ID         int64     `json:"id"`
+	UID        int64     `json:"uid"`
+	TargetID   int64     `json:"target_id"`
+	FollowType int32     `json:"follow_type"`
+	Action     string    `json:"action"` // follow | unfollow | restore
+	Source     string    `json:"source"`
+	Ctime      time.Time `json:"ctime"`
+}
+
+// GraphDeletion is the progress of erasing the graph of UID after its
+// account was deleted: how many follow, follower and topic rows are gone.
+type GraphDeletion struct {
+	UID       int64     `json:"uid"`
+	Done      bool      `json:"done"`
+	Follows   int64     `json:"follows"`
+	Followers int64     `json:"followers"`
+	Topics    int64     `json:"topics"`
+	Ctime     time.Time `json:"ctime"`
+	Mtime     time.Time `json:"mtime"`
+}
+
 func (t *Follow) TableName() string {
 	return "follow"
 }
@@ -53,3 +134,27 @@ func (t *FollowTopic) TableName() string {
 func (t *FollowTopicCount) TableName() string {
 	return "follow_topic_count"
 }
+
+func (t *FollowGroup) TableName() string {
+	return "follow_group"
+}
+
+func (t *FollowGroupMember) TableName() string {
+	return "follow_group_member"
+}
+
+func (t *FollowStatDaily) TableName() string {
+	return "follow_stat_daily"
+}
+
+func (t *FollowerHistory) TableName() string {
+	return "follower_history"
+}
+
+func (t *FollowAudit) TableName() string {
+	return "follow_audit"
+}
+
+func (t *GraphDeletion) TableName() string {
+	return "graph_deletion"
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"socialservice/util/constant"
+)
+
+const (
+	NotifyPageSize    = 500
+	NotifyMaxPageSize = 5000
+)
+
+// FollowSettings are what a follower chose for one followed user.
+type FollowSettings struct {
+	Notify  int32
+	Special bool
+}
+
+// followSettings checks the settings of a follow request; an unset notify
+// means every post.
+func followSettings(notify int32, special bool) (FollowSettings, error) {
+	switch notify {
+	case 0:
+		notify = constant.NotifyAll
+	case constant.NotifyAll, constant.NotifyHighlights, constant.NotifyNone:
+	default:
+		return FollowSettings{}, badRequest("unsupported notify %v", notify)
+	}
+	return FollowSettings{Notify: notify, Special: special}, nil
+}
+
+// updateFollowSettings fails with notFound unless uid follows toUID,
+// which the transaction updating the edge checks.
+func updateFollowSettings(ctx context.Context, uid, toUID int64, settings FollowSettings) error {
+	return dbUpdateFollowSettings(ctx, uid, toUID, settings)
+}
+
+// getNotifyFollowers pages through the followers of uid to notify of a
+// post pushed at level notify: followers of every post for an ordinary
+// post, and those of highlights too for a highlight. The cursor is the
+// follower row of the last one returned, so pages stay stable while the
+// fan-out runs; it reads MySQL only, as the follower cache holds no
+// settings.
+func getNotifyFollowers(ctx context.Context, uid int64, notify int32, specialOnly bool, lastID, limit int64) ([]notifyFollower, bool, int64, error) {
+	var levels []int32
+	switch notify {
+	case constant.NotifyAll:
+		levels = []int32{constant.NotifyAll}
+	case constant.NotifyHighlights:
+		levels = []int32{constant.NotifyAll, constant.NotifyHighlights}
+	default:
+		return nil, false, 0, badRequest("unsupported notify %v", notify)
+	}
+	if limit <= 0 {
+		limit = NotifyPageSize
+	}
+	if limit > NotifyMaxPageSize {
+		limit = NotifyMaxPageSize
+	}
+	followers, err := dbGetNotifyFollowers(ctx, uid, levels, specialOnly, lastID, limit)
+	if err != nil {
+		return nil, false, 0, err
+	}
+	hasMore := int64(len(followers)) > limit
+	if hasMore {
+		followers = followers[:limit]
+	}
+	var nextID int64
+	if len(followers) > 0 {
+		nextID = followers[len(followers)-1].ID
+	}
+	return followers, hasMore, nextID, nil
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	merrors "github.com/micro/go-micro/errors"
+	"net/http"
+	"testing"
+)
+
+func TestUpdateFollowSettingsOfLiveEdge(t *testing.T) {
+	env := newTestEnv(t)
+	settings := FollowSettings{Notify: 1}
+	env.sql.ExpectBegin()
+	env.sql.ExpectQuery("FROM `graph_deletion`").WillReturnRows(sqlmock.NewRows([]string{"uid"}))
+	expectLockFollows(env)
+	env.sql.ExpectRollback()
+	err := updateFollowSettings(testCtx, 1, 2, settings)
+	if e, ok := err.(*merrors.Error); !ok || e.Code != http.StatusNotFound {
+		t.Errorf("settings of an unfollowed edge = %v, want not found", err)
+	}
+
+	env.sql.ExpectBegin()
+	env.sql.ExpectQuery("FROM `graph_deletion`").WillReturnRows(sqlmock.NewRows([]string{"uid"}))
+	expectLockFollows(env, 2)
+	env.sql.ExpectExec("UPDATE `follow` SET .* WHERE .*deleted_at.* IS NULL").WillReturnResult(sqlmock.NewResult(0, 1))
+	env.sql.ExpectExec("UPDATE `follower` SET .* WHERE .*deleted_at.* IS NULL").WillReturnResult(sqlmock.NewResult(0, 1))
+	env.sql.ExpectCommit()
+	if err := updateFollowSettings(testCtx, 1, 2, settings); err != nil {
+		t.Error(err)
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
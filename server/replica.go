@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/jinzhu/gorm"
+	"go.uber.org/zap"
+	"socialservice/conf"
+	"socialservice/util/cast"
+	"socialservice/util/lifecycle"
+	"socialservice/util/logger"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	ReplicaPolicyRoundRobin = "round_robin"
+	ReplicaPolicyLeastConn  = "least_conn"
+
+	DefaultReplicaMaxLag   = 3 * time.Second
+	DefaultReplicaInterval = 5 * time.Second
+	ReplicaCheckTimeout    = time.Second
+)
+
+type replica struct {
+	addr    string
+	db      *gorm.DB
+	healthy int32
+	lag     int64 // seconds behind master, -1 when replication is broken
+}
+
+type replicaSet struct {
+	replicas []*replica
+	policy   string
+	maxLag   time.Duration
+	interval time.Duration
+	next     uint32
+}
+
+func newReplicaSet(config *conf.Conf) (*replicaSet, error) {
+	slaves := config.Slaves
+	if len(slaves) == 0 {
+		slaves = []conf.MysqlConf{config.Slave}
+	}
+	rs := &replicaSet{
+		replicas: make([]*replica, 0, len(slaves)),
+		policy:   config.Replica.Policy,
+		maxLag:   time.Duration(config.Replica.MaxLag) * time.Second,
+		interval: time.Duration(config.Replica.Interval) * time.Second,
+	}
+	if rs.policy == "" {
+		rs.policy = ReplicaPolicyRoundRobin
+	}
+	if rs.maxLag <= 0 {
+		rs.maxLag = DefaultReplicaMaxLag
+	}
+	if rs.interval <= 0 {
+		rs.interval = DefaultReplicaInterval
+	}
+	for _, v := range slaves {
+		db, err := conf.GetGorm(v)
+		if err != nil {
+			return nil, err
+		}
+		rs.replicas = append(rs.replicas, &replica{
+			addr:    fmt.Sprintf("%v:%v", v.Host, v.Port),
+			db:      db,
+			healthy: 1,
+		})
+	}
+	return rs, nil
+}
+
+// pick returns a healthy replica within the lag threshold, or nil when
+// reads have to fall back to the master.
+func (rs *replicaSet) pick() *gorm.DB {
+	available := make([]*replica, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		lag := atomic.LoadInt64(&r.lag)
+		if atomic.LoadInt32(&r.healthy) == 1 && lag >= 0 && time.Duration(lag)*time.Second <= rs.maxLag {
+			available = append(available, r)
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+	if rs.policy == ReplicaPolicyLeastConn {
+		best := available[0]
+		bestInUse := best.db.DB().Stats().InUse
+		for _, r := range available[1:] {
+			if inUse := r.db.DB().Stats().InUse; inUse < bestInUse {
+				best, bestInUse = r, inUse
+			}
+		}
+		return best.db
+	}
+	n := atomic.AddUint32(&rs.next, 1)
+	return available[int(n)%len(available)].db
+}
+
+func (rs *replicaSet) check() {
+	for _, r := range rs.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), ReplicaCheckTimeout)
+		healthy, lag := r.probe(ctx)
+		cancel()
+		if healthy {
+			atomic.StoreInt32(&r.healthy, 1)
+		} else {
+			atomic.StoreInt32(&r.healthy, 0)
+		}
+		atomic.StoreInt64(&r.lag, lag)
+	}
+}
+
+func (rs *replicaSet) close() error {
+	var rerr error
+	for _, r := range rs.replicas {
+		err := r.db.Close()
+		if err != nil && rerr == nil {
+			rerr = err
+		}
+	}
+	return rerr
+}
+
+func (rs *replicaSet) watch(ctx context.Context) {
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		rs.check()
+	}
+}
+
+func (r *replica) probe(ctx context.Context) (bool, int64) {
+	err := r.db.DB().PingContext(ctx)
+	if err != nil {
+		logger.Error(ctx, "replica ping", zap.String("addr", r.addr), logger.Err(err))
+		return false, 0
+	}
+	rows, err := r.db.DB().QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		logger.Error(ctx, "replica show slave status", zap.String("addr", r.addr), logger.Err(err))
+		return false, 0
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		// a reset replica or a standalone server: how stale it is cannot
+		// be told, so it is treated like one whose replication stopped
+		logger.Warn(ctx, "replica not replicating", zap.String("addr", r.addr))
+		return true, -1
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		logger.Error(ctx, "replica slave status columns", zap.String("addr", r.addr), logger.Err(err))
+		return false, 0
+	}
+	vals := make([]sql.RawBytes, len(cols))
+	dest := make([]interface{}, len(cols))
+	for i := range vals {
+		dest[i] = &vals[i]
+	}
+	err = rows.Scan(dest...)
+	if err != nil {
+		logger.Error(ctx, "replica scan slave status", zap.String("addr", r.addr), logger.Err(err))
+		return false, 0
+	}
+	for i, c := range cols {
+		if c != "Seconds_Behind_Master" {
+			continue
+		}
+		if vals[i] == nil {
+			logger.Warn(ctx, "replica replication stopped", zap.String("addr", r.addr))
+			return true, -1
+		}
+		return true, cast.ParseInt(string(vals[i]), -1)
+	}
+	return true, 0
+}
+
+func (rs *replicaSet) start() {
+	rs.check()
+	lifecycle.Run("replica watch", rs.watch)
+}
+
+// readDB returns the client reads for uid should go to: the master right
+// after uid wrote, otherwise a replica picked by the configured policy.
+func readDB(ctx context.Context, uid int64) *gorm.DB {
+	if cacheIsRecentWrite(ctx, uid) {
+		return dbCli
+	}
+	if db := slaves.pick(); db != nil {
+		return db
+	}
+	return dbCli
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+	"time"
+)
+
+func TestReplicaLag(t *testing.T) {
+	env := newTestEnv(t)
+	r := &replica{addr: "replica:3306", db: dbCli, healthy: 1}
+	rs := &replicaSet{replicas: []*replica{r}, maxLag: 3 * time.Second}
+	for _, c := range []struct {
+		name string
+		rows *sqlmock.Rows
+		lag  int64
+	}{
+		{"caught up", sqlmock.NewRows([]string{"Seconds_Behind_Master"}).AddRow("1"), 1},
+		{"stopped", sqlmock.NewRows([]string{"Seconds_Behind_Master"}).AddRow(nil), -1},
+		{"not replicating", sqlmock.NewRows([]string{"Seconds_Behind_Master"}), -1},
+	} {
+		env.sql.ExpectQuery("SHOW SLAVE STATUS").WillReturnRows(c.rows)
+		rs.check()
+		if r.lag != c.lag {
+			t.Errorf("%v: lag = %v, want %v", c.name, r.lag, c.lag)
+		}
+		if picked := rs.pick() != nil; picked != (c.lag >= 0) {
+			t.Errorf("%v: picked = %v", c.name, picked)
+		}
+	}
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"context"
+	"socialservice/util/constant"
+	"time"
+)
+
+const (
+	AuditFollow   = "follow"
+	AuditUnfollow = "unfollow"
+	AuditRestore  = "restore"
+
+	// RestoreMaxEdges is the most edges one RestoreFollows call brings
+	// back; the caller repeats it while it reports more.
+	RestoreMaxEdges = 500
+	// AuditMaxRows is the most audit rows AdminAudit returns.
+	AuditMaxRows = 1000
+)
+
+// RestoreReport lists what AdminRestore brought back.
+type RestoreReport struct {
+	Follows []int64 `json:"follows"`
+	Topics  []int64 `json:"topics"`
+}
+
+// restoreFollows brings back the users or topics uid unfollowed since
+// since, most recent first, with the settings they had. Group
+// memberships dropped by the unfollow are not restored.
+func restoreFollows(ctx context.Context, uid int64, followType int32, since time.Time) ([]int64, bool, error) {
+	ids, err := dbGetUnfollowed(ctx, uid, followType, since, RestoreMaxEdges+1)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(ids) > RestoreMaxEdges
+	if hasMore {
+		ids = ids[:RestoreMaxEdges]
+	}
+	restored := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		var ok bool
+		if followType == constant.FollowTypeTopic {
+			ok, err = dbRestoreFollowTopic(ctx, uid, id)
+		} else {
+			ok, err = dbRestoreFollow(ctx, uid, id)
+		}
+		if err != nil {
+			return restored, false, err
+		}
+		if !ok {
+			continue
+		}
+		restored = append(restored, id)
+		if followType == constant.FollowTypeTopic {
+			cacheMarkWrite(ctx, uid)
+			_ = cacheFollowTopic(ctx, uid, id)
+		} else {
+			cacheMarkWrite(ctx, uid, id)
+			_ = cacheFollow(ctx, uid, id)
+		}
+	}
+	if len(restored) > 0 && followType == constant.FollowTypePerson {
+		cacheDropSuggestions(ctx, uid)
+	}
+	return restored, hasMore, nil
+}
+
+// AdminRestore brings back every user and topic uid unfollowed since
+// since.
+func AdminRestore(ctx context.Context, uid int64, since time.Time) (*RestoreReport, error) {
+	report := &RestoreReport{Follows: []int64{}, Topics: []int64{}}
+	for _, t := range []struct {
+		followType int32
+		ids        *[]int64
+	}{{constant.FollowTypePerson, &report.Follows}, {constant.FollowTypeTopic, &report.Topics}} {
+		for {
+			ids, hasMore, err := restoreFollows(ctx, uid, t.followType, since)
+			*t.ids = append(*t.ids, ids...)
+			if err != nil {
+				return report, err
+			}
+			if !hasMore {
+				break
+			}
+		}
+	}
+	return report, nil
+}
+
+// AdminAudit returns the newest changes uid made to its follows.
+func AdminAudit(ctx context.Context, uid int64) ([]FollowAudit, error) {
+	return dbGetAudit(ctx, uid, AuditMaxRows)
+}
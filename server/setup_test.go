@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/jinzhu/gorm"
+	"socialservice/conf"
+	"socialservice/util/concurrent"
+	"socialservice/util/lru"
+	"testing"
+	"time"
+)
+
+// testEnv points the package globals at in-process stand-ins: miniredis
+// for Redis, sqlmock for MySQL and fakeMemcached for memcached.
+type testEnv struct {
+	redis *miniredis.Miniredis
+	sql   sqlmock.Sqlmock
+	mc    *fakeMemcached
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	setTunables(conf.DynamicConf{})
+	mr := miniredis.RunT(t)
+	rc := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() {
+		_ = rc.Close()
+	})
+	redisCli = rc
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dbCli, err = gorm.Open("mysql", db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = dbCli.Close()
+	})
+	slaves = &replicaSet{}
+
+	mc := newFakeMemcached(t)
+	mcCli = mc.client()
+	localCache = lru.New(100, time.Minute)
+	countCache = CountCacheRedis
+	t.Cleanup(func() {
+		countCache = CountCacheRedis
+	})
+	backfillPool = concurrent.NewPool("backfill", concurrent.PoolOptions{Workers: 2})
+	t.Cleanup(func() {
+		_ = backfillPool.Close(context.Background())
+	})
+	return &testEnv{redis: mr, sql: mock, mc: mc}
+}
+
+// eventually polls cond until it holds or a second has passed.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %v", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+var testCtx = context.Background()
@@ -4,78 +4,108 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-redis/redis/v8"
-	"socialservice/util/concurrent"
+	"socialservice/util/logger"
 )
 
-func follow(ctx context.Context, uid, toUID int64) error {
-	err := dbFollow(ctx, uid, toUID)
+// backfill refills a cache off the request path. It is best-effort: when
+// the pool turns the task away the next read simply misses again.
+func backfill(ctx context.Context, fn func(ctx context.Context)) {
+	err := backfillPool.Submit(ctx, fn)
+	if err != nil {
+		logger.Debug(ctx, "backfill rejected", logger.Err(err))
+	}
+}
+
+// pageOf cuts the page at cursor out of a full list read from MySQL, the
+// way cacheGetFollow pages through the cached set.
+func pageOf(uids []int64, cursor, offset int64) ([]int64, bool) {
+	if cursor >= int64(len(uids)) {
+		return []int64{}, false
+	}
+	end := cursor + offset
+	if end >= int64(len(uids)) {
+		return uids[cursor:], false
+	}
+	return uids[cursor:end], true
+}
+
+func follow(ctx context.Context, uid, toUID int64, settings FollowSettings, source FollowSource) error {
+	err := dbFollow(ctx, uid, toUID, settings, source)
 	if err != nil {
 		return err
 	}
+	cacheMarkWrite(ctx, uid, toUID)
+	// a cached suggestion may be the user just followed
+	cacheDropSuggestions(ctx, uid)
 	return cacheFollow(ctx, uid, toUID)
 }
 
 func unfollow(ctx context.Context, uid, toUID int64) error {
-	err := dbUnfollow(ctx, uid, toUID)
+	groupIDs, err := dbUnfollow(ctx, uid, toUID)
 	if err != nil {
 		return err
 	}
+	cacheMarkWrite(ctx, uid, toUID)
+	cacheRemFromGroups(ctx, uid, toUID, groupIDs)
 	return cacheUnfollow(ctx, uid, toUID)
 }
 
 func getFollow(ctx context.Context, uid, lastID, offset int64) ([]int64, bool, error) {
-	if offset == 0 {
-		offset = DefaultOffset
-	}
+	offset = pageSize(offset)
 	key := fmt.Sprintf(RedisKeyZFollow, uid)
 	uids, hasMore, err := cacheGetFollow(ctx, key, lastID, offset)
 	if err != nil {
-		var utMap map[int64]int64
-		uids, utMap, err = dbGetFollow(ctx, uid)
+		// the backfill gets the whole list, not the page cut from it below
+		all, utMap, err := dbGetFollow(ctx, uid)
 		if err != nil {
 			return nil, false, err
 		}
-		cacheSetFollow(ctx, key, uids, utMap)
-		uids = uids[:offset]
-		if len(uids) > int(offset) {
-			hasMore = true
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollow(ctx, key, all, utMap)
+			})
 		}
+		uids, hasMore = pageOf(all, lastID, offset)
 	}
 	return uids, hasMore, nil
 }
 
 func getFollower(ctx context.Context, uid, lastID, offset int64) ([]int64, bool, error) {
-	if offset == 0 {
-		offset = DefaultOffset
-	}
+	offset = pageSize(offset)
 	key := fmt.Sprintf(RedisKeyZFollower, uid)
 	uids, hasMore, err := cacheGetFollow(ctx, key, lastID, offset)
 	if err != nil {
-		var utMap map[int64]int64
-		uids, utMap, err = dbGetFollower(ctx, uid)
+		all, utMap, err := dbGetFollower(ctx, uid)
 		if err != nil {
 			return nil, false, err
 		}
-		cacheSetFollow(ctx, key, uids, utMap)
-		uids = uids[:offset]
-		if len(uids) > int(offset) {
-			hasMore = true
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollow(ctx, key, all, utMap)
+			})
 		}
+		uids, hasMore = pageOf(all, lastID, offset)
 	}
 	return uids, hasMore, nil
 
 }
 
 func getFollowCount(ctx context.Context, uid int64) (int64, int64, error) {
-	followCnt, followerCnt, err := cacheGetFollowCount(ctx, uid)
+	getCount, setCount := cacheGetFollowCount, cacheSetFollowCount
+	if countCache == CountCacheMemcache {
+		getCount, setCount = mcGetFollowCount, mcSetFollowCount
+	}
+	followCnt, followerCnt, err := getCount(ctx, uid)
 	if err != nil {
 		followCnt, followerCnt, err = dbGetFollowCount(ctx, uid)
 		if err != nil {
 			return 0, 0, err
 		}
-		concurrent.Go(func() {
-			cacheSetFollowCount(ctx, uid, followCnt, followerCnt)
-		})
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				setCount(ctx, uid, followCnt, followerCnt)
+			})
+		}
 	}
 	return followCnt, followerCnt, nil
 }
@@ -85,48 +115,54 @@ func followTopic(ctx context.Context, uid, topicID int64) error {
 	if err != nil {
 		return err
 	}
+	cacheMarkWrite(ctx, uid)
 	return cacheFollowTopic(ctx, uid, topicID)
 }
 
 func unfollowTopic(ctx context.Context, uid, topicID int64) error {
-	err := dbUnfollow(ctx, uid, topicID)
+	err := dbUnfollowTopic(ctx, uid, topicID)
 	if err != nil {
 		return err
 	}
+	cacheMarkWrite(ctx, uid)
 	return cacheUnfollowTopic(ctx, uid, topicID)
 }
 
 func getFollowTopic(ctx context.Context, uid, lastID, offset int64) ([]int64, bool, error) {
-	if offset == 0 {
-		offset = DefaultOffset
-	}
+	offset = pageSize(offset)
 	key := fmt.Sprintf(RedisKeyZFollowTopic, uid)
 	uids, hasMore, err := cacheGetFollow(ctx, key, lastID, offset)
 	if err != nil {
-		var utMap map[int64]int64
-		uids, utMap, err = dbGetFollowTopic(ctx, uid)
+		all, utMap, err := dbGetFollowTopic(ctx, uid)
 		if err != nil {
 			return nil, false, err
 		}
-		cacheSetFollow(ctx, key, uids, utMap)
-		uids = uids[:offset]
-		if len(uids) > int(offset) {
-			hasMore = true
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollow(ctx, key, all, utMap)
+			})
 		}
+		uids, hasMore = pageOf(all, lastID, offset)
 	}
 	return uids, hasMore, nil
 }
 
 func getFollowTopicCount(ctx context.Context, uid int64) (int64, error) {
-	followCnt, err := cacheGetFollowTopicCount(ctx, uid)
+	getCount, setCount := cacheGetFollowTopicCount, cacheSetFollowTopicCount
+	if countCache == CountCacheMemcache {
+		getCount, setCount = mcGetFollowTopicCount, mcSetFollowTopicCount
+	}
+	followCnt, err := getCount(ctx, uid)
 	if err != nil {
 		followCnt, err = dbGetFollowTopicCount(ctx, uid)
 		if err != nil {
 			return 0, err
 		}
-		concurrent.Go(func() {
-			cacheSetFollowTopicCount(ctx, uid, followCnt)
-		})
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				setCount(ctx, uid, followCnt)
+			})
+		}
 	}
 	return followCnt, nil
 }
@@ -139,26 +175,49 @@ func getAllFollow(ctx context.Context, uid int64, cursor uint64) ([]int64, uint6
 		if err != nil {
 			return nil, 0, err
 		}
-		concurrent.Go(func() {
-			cacheSetFollow(ctx, key, uids, utMap)
-		})
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollow(ctx, key, uids, utMap)
+			})
+		}
 		return uids, 0, nil
 	}
 	return uids, c, err
 }
 
 func getAllFollower(ctx context.Context, uid int64, cursor uint64) ([]int64, uint64, error) {
-	key := fmt.Sprintf(RedisKeyZFollow, uid)
+	key := fmt.Sprintf(RedisKeyZFollower, uid)
 	uids, c, err := getAllStream(ctx, key, cursor)
 	if err == redis.Nil {
-		uids, utMap, err := dbGetFollow(ctx, uid)
+		uids, utMap, err := dbGetFollower(ctx, uid)
 		if err != nil {
 			return nil, 0, err
 		}
-		concurrent.Go(func() {
-			cacheSetFollow(ctx, key, uids, utMap)
-		})
+		if !cacheIsRecentWrite(ctx, uid) {
+			backfill(ctx, func(ctx context.Context) {
+				cacheSetFollow(ctx, key, uids, utMap)
+			})
+		}
 		return uids, 0, nil
 	}
 	return uids, c, err
 }
+
+// getCommonFollowers returns the users viewer follows who also follow
+// target, for "followed by people you follow".
+func getCommonFollowers(ctx context.Context, viewer, target, limit int64) ([]int64, int64, error) {
+	limit = pageSize(limit)
+	// the intersection walks a whole set, so the answer is kept a while
+	if uids, total, ok := cacheGetCommonResult(ctx, viewer, target, limit); ok {
+		return uids, total, nil
+	}
+	uids, total, ok, err := cacheGetCommon(ctx, viewer, target, limit)
+	if err != nil || !ok {
+		uids, total, err = dbGetCommon(ctx, viewer, target, limit)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	cacheSetCommonResult(ctx, viewer, target, limit, uids, total)
+	return uids, total, nil
+}
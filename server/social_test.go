@@ -0,0 +1,89 @@
+package server
+
+import (
+	"fmt"
+	"github.com/DATA-DOG/go-sqlmock"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func sortedUIDs(uids []int64) []int64 {
+	sort.Slice(uids, func(i, j int) bool {
+		return uids[i] < uids[j]
+	})
+	return uids
+}
+
+func TestGetAllFollowerFromCache(t *testing.T) {
+	env := newTestEnv(t)
+	env.redis.ZAdd(fmt.Sprintf(RedisKeyZFollower, 1), 1700000000, "2")
+	env.redis.ZAdd(fmt.Sprintf(RedisKeyZFollower, 1), 1700000001, "3")
+	// the follow list of the same user must not leak into its followers
+	env.redis.ZAdd(fmt.Sprintf(RedisKeyZFollow, 1), 1700000002, "4")
+
+	uids, cursor, err := getAllFollower(testCtx, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != 0 || !reflect.DeepEqual(sortedUIDs(uids), []int64{2, 3}) {
+		t.Errorf("getAllFollower = %v, cursor %v, want [2 3] and no scores", uids, cursor)
+	}
+}
+
+func TestGetAllFollowerFallsBackToMySQL(t *testing.T) {
+	env := newTestEnv(t)
+	env.sql.ExpectQuery("SELECT follower_uid, ctime FROM `follower` WHERE").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"follower_uid", "ctime"}).
+			AddRow(3, time.Unix(1700000001, 0)).
+			AddRow(2, time.Unix(1700000000, 0)))
+
+	uids, cursor, err := getAllFollower(testCtx, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cursor != 0 || !reflect.DeepEqual(uids, []int64{3, 2}) {
+		t.Errorf("getAllFollower = %v, cursor %v, want [3 2] from MySQL", uids, cursor)
+	}
+	key := fmt.Sprintf(RedisKeyZFollower, 1)
+	eventually(t, "the backfill", func() bool {
+		members, _ := env.redis.ZMembers(key)
+		return len(members) == 2
+	})
+	if err := env.sql.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetCommonFollowersCachesAnswer(t *testing.T) {
+	env := newTestEnv(t)
+	follow, follower := fmt.Sprintf(RedisKeyZFollow, 1), fmt.Sprintf(RedisKeyZFollower, 9)
+	for i, uid := range []string{"2", "3", "4"} {
+		env.redis.ZAdd(follow, float64(1700000000+i), uid)
+	}
+	for i, uid := range []string{"3", "4", "5"} {
+		env.redis.ZAdd(follower, float64(1700000000+i), uid)
+	}
+
+	uids, total, err := getCommonFollowers(testCtx, 1, 9, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 2 || !reflect.DeepEqual(uids, []int64{4, 3}) {
+		t.Fatalf("getCommonFollowers = %v, %v, want [4 3], 2", uids, total)
+	}
+
+	// until CommonTTL passes the answer is not worked out again
+	env.redis.ZRem(follower, "4")
+	uids, total, err = getCommonFollowers(testCtx, 1, 9, 10)
+	if err != nil || total != 2 || len(uids) != 2 {
+		t.Errorf("cached getCommonFollowers = %v, %v, %v, want the first answer", uids, total, err)
+	}
+	env.redis.FastForward(CommonTTL)
+	uids, total, err = getCommonFollowers(testCtx, 1, 9, 10)
+	if err != nil || total != 1 || !reflect.DeepEqual(uids, []int64{3}) {
+		t.Errorf("getCommonFollowers after CommonTTL = %v, %v, %v, want [3], 1", uids, total, err)
+	}
+}
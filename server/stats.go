@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	FollowSourceMaxLen = 32
+	// FollowStatsMaxDays bounds the range of one GetFollowStats call.
+	FollowStatsMaxDays = 366
+
+	// FollowSourceAdmin marks the follows made by operators.
+	FollowSourceAdmin = "admin"
+
+	statDayLayout = "2006-01-02"
+)
+
+// FollowSource is where a follow was made: the surface, such as search,
+// recommendation or profile, and the client it was made on.
+type FollowSource struct {
+	Source string
+	Client string
+}
+
+// followSource checks the attribution of a follow request. Both parts are
+// optional and kept lower case so the stats group them.
+func followSource(source, client string) (FollowSource, error) {
+	source = strings.ToLower(strings.TrimSpace(source))
+	client = strings.ToLower(strings.TrimSpace(client))
+	if len(source) > FollowSourceMaxLen {
+		return FollowSource{}, badRequest("source is %v bytes, at most %v are allowed", len(source), FollowSourceMaxLen)
+	}
+	if len(client) > FollowSourceMaxLen {
+		return FollowSource{}, badRequest("client is %v bytes, at most %v are allowed", len(client), FollowSourceMaxLen)
+	}
+	return FollowSource{Source: source, Client: client}, nil
+}
+
+// statDay is the UTC date t is counted on.
+func statDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// parseDayRange parses an inclusive range of days of at most maxDays; to
+// defaults to today and from to the maxDays ending on to.
+func parseDayRange(from, to string, maxDays int) (time.Time, time.Time, error) {
+	end := statDay(time.Now())
+	if to != "" {
+		t, err := time.Parse(statDayLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, badRequest("bad to %q, want YYYY-MM-DD", to)
+		}
+		end = t
+	}
+	start := end.AddDate(0, 0, 1-maxDays)
+	if from != "" {
+		t, err := time.Parse(statDayLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, badRequest("bad from %q, want YYYY-MM-DD", from)
+		}
+		start = t
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, badRequest("from %v is after to %v", from, to)
+	}
+	if days := int(end.Sub(start).Hours()/24) + 1; days > maxDays {
+		return time.Time{}, time.Time{}, badRequest("range is %v days, at most %v are allowed", days, maxDays)
+	}
+	return start, end, nil
+}
+
+// getFollowStats returns one entry per day from from to to, days without
+// any follow or unfollow included as zeros so callers can chart them
+// directly.
+func getFollowStats(ctx context.Context, uid int64, from, to time.Time) ([]FollowStatDaily, error) {
+	stored, err := dbGetFollowStats(ctx, uid, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byDay := make(map[string]FollowStatDaily, len(stored))
+	for _, s := range stored {
+		byDay[s.Day.Format(statDayLayout)] = s
+	}
+	stats := make([]FollowStatDaily, 0, int(to.Sub(from).Hours()/24)+1)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		s, ok := byDay[day.Format(statDayLayout)]
+		if !ok {
+			s = FollowStatDaily{UID: uid}
+		}
+		s.Day = day
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"socialservice/util/concurrent"
+	"sort"
+)
+
+const (
+	// SuggestMaxCandidates is how many suggestions are ranked and cached
+	// per user, and so the most a caller can ask for.
+	SuggestMaxCandidates = 100
+	// SuggestDBConcurrency bounds the MySQL reads of a walk whose follow
+	// sets are not cached.
+	SuggestDBConcurrency = 8
+)
+
+type suggestion struct {
+	uid    int64
+	mutual int64
+}
+
+// getFollowSuggestions returns people uid may know: users followed by the
+// people uid follows, ranked by how many of them follow each. Blocked
+// users are not filtered out, as the service stores no blocks.
+func getFollowSuggestions(ctx context.Context, uid, limit int64) ([]suggestion, error) {
+	limit = pageSize(limit)
+	if limit > SuggestMaxCandidates {
+		limit = SuggestMaxCandidates
+	}
+	list, ok, err := cacheGetSuggestions(ctx, uid, limit)
+	if err == nil && ok {
+		return list, nil
+	}
+	all, err := walkSuggestions(ctx, uid)
+	if err != nil {
+		return nil, err
+	}
+	backfill(ctx, func(ctx context.Context) {
+		cacheSetSuggestions(ctx, uid, all)
+	})
+	if int64(len(all)) > limit {
+		return all[:limit], nil
+	}
+	return all, nil
+}
+
+// walkSuggestions does the two hop walk. Each hop reads at most
+// suggest_fanout of the newest follows per user, so its cost is bounded
+// whatever the shape of the graph.
+func walkSuggestions(ctx context.Context, uid int64) ([]suggestion, error) {
+	fanout := settings().suggestFanout
+	first, err := getFollowHead(ctx, []int64{uid}, fanout)
+	if err != nil {
+		return nil, err
+	}
+	hop := first[uid]
+	if len(hop) == 0 {
+		return nil, nil
+	}
+	second, err := getFollowHead(ctx, hop, fanout)
+	if err != nil {
+		return nil, err
+	}
+	followed := make(map[int64]bool, len(hop))
+	for _, id := range hop {
+		followed[id] = true
+	}
+	mutual := make(map[int64]int64)
+	for _, f := range hop {
+		for _, id := range second[f] {
+			if id != uid && !followed[id] {
+				mutual[id]++
+			}
+		}
+	}
+	list := make([]suggestion, 0, len(mutual))
+	for id, n := range mutual {
+		list = append(list, suggestion{uid: id, mutual: n})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].mutual != list[j].mutual {
+			return list[i].mutual > list[j].mutual
+		}
+		return list[i].uid < list[j].uid
+	})
+
+	// the first hop only saw the newest follows, so the best candidates
+	// may still be users uid already follows
+	if len(list) > 2*SuggestMaxCandidates {
+		list = list[:2*SuggestMaxCandidates]
+	}
+	ids := make([]int64, 0, len(list))
+	for _, s := range list {
+		ids = append(ids, s.uid)
+	}
+	following, err := isFollowing(ctx, uid, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := list[:0]
+	for _, s := range list {
+		if !following[s.uid] {
+			res = append(res, s)
+		}
+	}
+	if len(res) > SuggestMaxCandidates {
+		res = res[:SuggestMaxCandidates]
+	}
+	return res, nil
+}
+
+// getFollowHead reads the newest limit follows of every uid, from Redis
+// where the set is cached and from MySQL otherwise.
+func getFollowHead(ctx context.Context, uids []int64, limit int64) (map[int64][]int64, error) {
+	heads, err := cacheGetFollowHead(ctx, uids, limit)
+	if err != nil {
+		heads = make(map[int64][]int64, len(uids))
+	}
+	var missing []int64
+	for _, uid := range uids {
+		if _, ok := heads[uid]; !ok {
+			missing = append(missing, uid)
+		}
+	}
+	dbHeads := make([][]int64, len(missing))
+	err = concurrent.Fanout(ctx, len(missing), SuggestDBConcurrency, func(ctx context.Context, i int) error {
+		var err error
+		dbHeads[i], err = dbGetFollowHead(ctx, missing[i], limit)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, uid := range missing {
+		heads[uid] = dbHeads[i]
+	}
+	return heads, nil
+}
+
+// isFollowing reports which of ids uid follows.
+func isFollowing(ctx context.Context, uid int64, ids []int64) (map[int64]bool, error) {
+	if len(ids) == 0 {
+		return map[int64]bool{}, nil
+	}
+	following, ok, err := cacheGetFollowing(ctx, uid, ids)
+	if err == nil && ok {
+		return following, nil
+	}
+	return dbGetFollowing(ctx, uid, ids)
+}
@@ -1,14 +1,16 @@
 package cast
 
 import (
-	"log"
+	"context"
+	"go.uber.org/zap"
+	"socialservice/util/logger"
 	"strconv"
 )
 
 func Atoi(s string, defVal int32) int32 {
 	res, err := strconv.Atoi(s)
 	if err != nil {
-		log.Printf("Atoi %v err %v", s, err)
+		logger.Warn(context.Background(), "Atoi", zap.String("s", s), logger.Err(err))
 		return defVal
 	}
 	return int32(res)
@@ -17,7 +19,7 @@ func Atoi(s string, defVal int32) int32 {
 func ParseInt(s string, defVal int64) int64 {
 	res, err := strconv.ParseInt(s, 10, 64)
 	if err != nil {
-		log.Printf("ParseInt %v err %v", s, err)
+		logger.Warn(context.Background(), "ParseInt", zap.String("s", s), logger.Err(err))
 		return defVal
 	}
 	return res
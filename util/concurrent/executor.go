@@ -2,8 +2,6 @@ package concurrent
 
 import (
 	"context"
-	"log"
-	"runtime/debug"
 	"sync"
 )
 
@@ -20,7 +18,7 @@ func (ex *WaitGroup) Run(fn func()) {
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println("executor", err, string(debug.Stack()))
+				recovered(context.Background(), "Run", err)
 			}
 			ex.wg.Done()
 		}()
@@ -30,11 +28,12 @@ func (ex *WaitGroup) Run(fn func()) {
 
 func (ex *WaitGroup) RunC(ctx context.Context, fn func()) error {
 	ex.wg.Add(1)
-	sig := make(chan struct{})
+	// buffered so the goroutine can finish after the caller gave up on ctx
+	sig := make(chan struct{}, 1)
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println("executor.runc: panic", err, "\n", string(debug.Stack()))
+				recovered(context.Background(), "RunC", err)
 			}
 			sig <- struct{}{}
 		}()
@@ -59,7 +58,7 @@ func Go(fn func()) {
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println("Go:", err, "\n", string(debug.Stack()))
+				recovered(context.Background(), "Go", err)
 			}
 		}()
 		fn()
@@ -70,7 +69,7 @@ func GoM(fn, pre, post func()) {
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println("GoM:", err, "\n", string(debug.Stack()))
+				recovered(context.Background(), "GoM", err)
 			}
 		}()
 		if pre != nil {
@@ -84,11 +83,12 @@ func GoM(fn, pre, post func()) {
 }
 
 func GoC(ctx context.Context, fn func()) (rerr error) {
-	sig := make(chan struct{})
+	// buffered so the goroutine can finish after the caller gave up on ctx
+	sig := make(chan struct{}, 1)
 	go func() {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Println("GoC:", err, "\n", string(debug.Stack()))
+				recovered(context.Background(), "GoC", err)
 			}
 			sig <- struct{}{}
 		}()
@@ -0,0 +1,113 @@
+package concurrent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PanicError is what a Group task that panicked returns.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Group runs tasks on their own goroutines and reports the first error.
+// Unlike WaitGroup every task is waited for, and a panic becomes an error
+// instead of vanishing into the log.
+type Group struct {
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	sem     chan struct{}
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup returns a Group whose tasks all run to completion whatever the
+// others return.
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// WithContext returns a Group and a ctx derived from ctx that is cancelled
+// by the first task to fail, or once Wait returns.
+func WithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit caps the tasks running at once; Go blocks while the cap is
+// reached. It must be called before the first Go.
+func (g *Group) SetLimit(n int) {
+	if n <= 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+func (g *Group) Go(fn func() error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := recovered(context.Background(), "Group", r)
+				g.fail(&PanicError{Value: r, Stack: stack})
+			}
+			if g.sem != nil {
+				<-g.sem
+			}
+			g.wg.Done()
+		}()
+		if err := fn(); err != nil {
+			g.fail(err)
+		}
+	}()
+}
+
+func (g *Group) fail(err error) {
+	g.errOnce.Do(func() {
+		g.err = err
+		if g.cancel != nil {
+			g.cancel()
+		}
+	})
+}
+
+// Wait blocks until every task has returned and gives back the first error.
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+	return g.err
+}
+
+// Fanout calls fn for every index in [0, n) with at most limit calls in
+// flight, zero meaning no limit. Results are collected by having fn write
+// to its own slot of a slice the caller sized to n. The first error cancels
+// the ctx handed to the remaining calls and is returned.
+func Fanout(ctx context.Context, n, limit int, fn func(ctx context.Context, i int) error) error {
+	g, gctx := WithContext(ctx)
+	g.SetLimit(limit)
+	started := 0
+	for ; started < n && gctx.Err() == nil; started++ {
+		i := started
+		g.Go(func() error {
+			return fn(gctx, i)
+		})
+	}
+	err := g.Wait()
+	if err == nil && started < n {
+		// ctx itself was cancelled before every call got started
+		err = ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,142 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupFirstErrorCancels(t *testing.T) {
+	g, ctx := WithContext(context.Background())
+	first := errors.New("first")
+	g.Go(func() error {
+		return first
+	})
+	g.Go(func() error {
+		select {
+		case <-ctx.Done():
+			return errors.New("second")
+		case <-time.After(time.Second):
+			t.Error("ctx was not cancelled by the failing task")
+			return nil
+		}
+	})
+	if err := g.Wait(); err != first {
+		t.Errorf("Wait = %v, want the first error", err)
+	}
+	if ctx.Err() == nil {
+		t.Error("ctx outlived Wait")
+	}
+}
+
+func TestGroupWaitsForAll(t *testing.T) {
+	g := NewGroup()
+	var done int32
+	for i := 0; i < 10; i++ {
+		i := i
+		g.Go(func() error {
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&done, 1)
+			if i == 0 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err == nil {
+		t.Error("Wait lost the error")
+	}
+	if done != 10 {
+		t.Errorf("Wait returned with %v of 10 tasks done", done)
+	}
+}
+
+func TestGroupLimit(t *testing.T) {
+	const limit = 3
+	g := NewGroup()
+	g.SetLimit(limit)
+	var running, peak int32
+	for i := 0; i < 20; i++ {
+		g.Go(func() error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(2 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if peak > limit {
+		t.Errorf("%v tasks ran at once, limit %v", peak, limit)
+	}
+}
+
+func TestGroupPanic(t *testing.T) {
+	g := NewGroup()
+	g.Go(func() error {
+		panic("boom")
+	})
+	err := g.Wait()
+	var perr *PanicError
+	if !errors.As(err, &perr) || perr.Value != "boom" || len(perr.Stack) == 0 {
+		t.Errorf("Wait = %#v, want a *PanicError with the value and stack", err)
+	}
+}
+
+func TestFanoutCollectsResults(t *testing.T) {
+	const n = 50
+	results := make([]int, n)
+	err := Fanout(context.Background(), n, 4, func(ctx context.Context, i int) error {
+		results[i] = i * i
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r != i*i {
+			t.Fatalf("results[%v] = %v, want %v", i, r, i*i)
+		}
+	}
+}
+
+func TestFanoutStopsOnError(t *testing.T) {
+	var started int32
+	failed := errors.New("failed")
+	err := Fanout(context.Background(), 100, 1, func(ctx context.Context, i int) error {
+		atomic.AddInt32(&started, 1)
+		if i == 2 {
+			return failed
+		}
+		return nil
+	})
+	if err != failed {
+		t.Errorf("Fanout = %v, want the task's error", err)
+	}
+	// with one call in flight at most one more starts after the failure
+	if started > 4 {
+		t.Errorf("%v calls started after the third failed", started)
+	}
+}
+
+func TestFanoutCancelledCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := Fanout(ctx, 10, 0, func(ctx context.Context, i int) error {
+		called = true
+		return nil
+	})
+	if err != context.Canceled || called {
+		t.Errorf("Fanout on a cancelled ctx = %v, called %v", err, called)
+	}
+}
@@ -0,0 +1,37 @@
+package concurrent
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"runtime/debug"
+	"socialservice/util/logger"
+)
+
+// Hooks let the binary count what this package sees without the package
+// depending on the metrics it is counted in. Set them once at start-up,
+// before the first goroutine is started; nil hooks are skipped.
+var (
+	// OnPanic is told about every panic recovered here, by the name of the
+	// helper that recovered it.
+	OnPanic func(where string)
+	// OnPoolReject is told about every task a Pool turns away.
+	OnPoolReject func(pool string)
+)
+
+// recovered reports a panic recovered by the helper where and returns its
+// stack.
+func recovered(ctx context.Context, where string, r interface{}, fields ...zap.Field) []byte {
+	if OnPanic != nil {
+		OnPanic(where)
+	}
+	stack := debug.Stack()
+	fields = append(fields, zap.Any("panic", r), zap.ByteString("stack", stack))
+	logger.Error(ctx, where+" panic", fields...)
+	return stack
+}
+
+func poolRejected(pool string) {
+	if OnPoolReject != nil {
+		OnPoolReject(pool)
+	}
+}
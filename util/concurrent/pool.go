@@ -0,0 +1,190 @@
+package concurrent
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	OverflowDrop       = "drop"        // reject the task
+	OverflowBlock      = "block"       // wait for room until the caller's ctx is done
+	OverflowCallerRuns = "caller_runs" // run the task on the submitting goroutine
+
+	DefaultPoolWorkers   = 32
+	DefaultPoolQueueSize = 1024
+)
+
+var (
+	ErrPoolFull   = errors.New("pool queue full")
+	ErrPoolClosed = errors.New("pool closed")
+)
+
+type PoolOptions struct {
+	Workers     int
+	QueueSize   int
+	Overflow    string
+	TaskTimeout time.Duration // zero leaves tasks unbounded
+}
+
+type task struct {
+	ctx context.Context
+	fn  func(ctx context.Context)
+}
+
+// Pool runs tasks on a fixed number of workers fed by a bounded queue.
+type Pool struct {
+	name  string
+	opts  PoolOptions
+	tasks chan task
+	// done is closed by Close. tasks is never closed, so a Submit that
+	// races with Close cannot panic; it may at worst queue a task no
+	// worker is left to run, which a best-effort pool can live with.
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+func NewPool(name string, opts PoolOptions) *Pool {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultPoolWorkers
+	}
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = DefaultPoolQueueSize
+	}
+	if opts.Overflow == "" {
+		opts.Overflow = OverflowDrop
+	}
+	p := &Pool{
+		name:  name,
+		opts:  opts,
+		tasks: make(chan task, opts.QueueSize),
+		done:  make(chan struct{}),
+	}
+	p.wg.Add(opts.Workers)
+	for i := 0; i < opts.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Submit queues fn. The task gets a copy of ctx that keeps its values, such
+// as the trace and log fields, but not its deadline or cancellation, so it
+// can outlive the request that queued it.
+func (p *Pool) Submit(ctx context.Context, fn func(ctx context.Context)) error {
+	t := task{ctx: Detach(ctx), fn: fn}
+	select {
+	case <-p.done:
+		return ErrPoolClosed
+	default:
+	}
+	select {
+	case p.tasks <- t:
+		return nil
+	default:
+	}
+	switch p.opts.Overflow {
+	case OverflowBlock:
+		select {
+		case p.tasks <- t:
+			return nil
+		case <-p.done:
+			return ErrPoolClosed
+		case <-ctx.Done():
+			poolRejected(p.name)
+			return ctx.Err()
+		}
+	case OverflowCallerRuns:
+		p.run(t)
+		return nil
+	default:
+		poolRejected(p.name)
+		return ErrPoolFull
+	}
+}
+
+// Queued returns how many tasks are waiting for a worker.
+func (p *Pool) Queued() int {
+	return len(p.tasks)
+}
+
+// Close stops taking tasks, releases the submitters blocked on a full
+// queue and waits until the queued tasks have run or ctx is done.
+func (p *Pool) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		close(p.done)
+	})
+	finished := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Pool) work() {
+	defer p.wg.Done()
+	for {
+		select {
+		case t := <-p.tasks:
+			p.run(t)
+		case <-p.done:
+			// run what was queued before Close, then stop
+			for {
+				select {
+				case t := <-p.tasks:
+					p.run(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (p *Pool) run(t task) {
+	defer func() {
+		if err := recover(); err != nil {
+			recovered(t.ctx, "Pool", err, zap.String("pool", p.name))
+		}
+	}()
+	ctx := t.ctx
+	if p.opts.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.opts.TaskTimeout)
+		defer cancel()
+	}
+	t.fn(ctx)
+}
+
+type detachedContext struct {
+	parent context.Context
+}
+
+// Detach returns a context carrying the values of ctx that is never done.
+func Detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,212 @@
+package concurrent
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type ctxKey struct{}
+
+// busyPool returns a pool whose only worker is stuck until release is
+// called and whose queue of one is full.
+func busyPool(t *testing.T, overflow string) (*Pool, func()) {
+	t.Helper()
+	p := NewPool("test", PoolOptions{Workers: 1, QueueSize: 1, Overflow: overflow})
+	block := make(chan struct{})
+	started := make(chan struct{})
+	if err := p.Submit(context.Background(), func(context.Context) {
+		close(started)
+		<-block
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := p.Submit(context.Background(), func(context.Context) {}); err != nil {
+		t.Fatal(err)
+	}
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(block)
+		})
+	}
+	t.Cleanup(release)
+	return p, release
+}
+
+func TestPoolRunsQueuedTasksBeforeClose(t *testing.T) {
+	p := NewPool("test", PoolOptions{Workers: 2})
+	var ran int32
+	for i := 0; i < 100; i++ {
+		if err := p.Submit(context.Background(), func(context.Context) {
+			atomic.AddInt32(&ran, 1)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran != 100 {
+		t.Errorf("ran %v of 100 queued tasks", ran)
+	}
+	if err := p.Submit(context.Background(), func(context.Context) {}); err != ErrPoolClosed {
+		t.Errorf("Submit after Close = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestPoolDrop(t *testing.T) {
+	var rejected []string
+	OnPoolReject = func(pool string) {
+		rejected = append(rejected, pool)
+	}
+	defer func() {
+		OnPoolReject = nil
+	}()
+	p, _ := busyPool(t, OverflowDrop)
+	if err := p.Submit(context.Background(), func(context.Context) {}); err != ErrPoolFull {
+		t.Errorf("Submit to a full pool = %v, want ErrPoolFull", err)
+	}
+	if len(rejected) != 1 || rejected[0] != "test" {
+		t.Errorf("rejections reported = %v", rejected)
+	}
+	if p.Queued() != 1 {
+		t.Errorf("Queued = %v, want 1", p.Queued())
+	}
+}
+
+func TestPoolCallerRuns(t *testing.T) {
+	p, _ := busyPool(t, OverflowCallerRuns)
+	ran := false
+	if err := p.Submit(context.Background(), func(context.Context) {
+		ran = true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("task was not run by the caller")
+	}
+}
+
+func TestPoolBlockGivesUpWithCtx(t *testing.T) {
+	p, _ := busyPool(t, OverflowBlock)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func(context.Context) {}); err != context.DeadlineExceeded {
+		t.Errorf("blocked Submit = %v, want DeadlineExceeded", err)
+	}
+}
+
+// TestPoolCloseReleasesBlockedSubmit checks that Close neither waits for
+// a submitter blocked on a full queue nor lets it queue afterwards.
+func TestPoolCloseReleasesBlockedSubmit(t *testing.T) {
+	p, release := busyPool(t, OverflowBlock)
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- p.Submit(context.Background(), func(context.Context) {})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Close with a stuck worker = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case err := <-submitted:
+		if err != ErrPoolClosed {
+			t.Errorf("blocked Submit = %v, want ErrPoolClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("blocked Submit was not released by Close")
+	}
+	release()
+	if err := p.Close(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPoolCloseWithCallerRunning(t *testing.T) {
+	p, release := busyPool(t, OverflowCallerRuns)
+	inline := make(chan struct{})
+	go func() {
+		_ = p.Submit(context.Background(), func(context.Context) {
+			<-inline
+		})
+	}()
+	time.Sleep(10 * time.Millisecond)
+	release()
+	closed := make(chan error, 1)
+	go func() {
+		closed <- p.Close(context.Background())
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close waited for a task running on the caller")
+	}
+	close(inline)
+}
+
+func TestPoolTaskContext(t *testing.T) {
+	p := NewPool("test", PoolOptions{Workers: 1, TaskTimeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "v"))
+	cancel()
+	// checked inside the task, whose ctx is cancelled once it returns
+	type seen struct {
+		err         error
+		value       interface{}
+		hasDeadline bool
+	}
+	got := make(chan seen, 1)
+	if err := p.Submit(ctx, func(ctx context.Context) {
+		_, ok := ctx.Deadline()
+		got <- seen{err: ctx.Err(), value: ctx.Value(ctxKey{}), hasDeadline: ok}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s := <-got
+	if s.err != nil {
+		t.Errorf("task inherited the cancellation of its submitter: %v", s.err)
+	}
+	if s.value != "v" {
+		t.Error("task lost the values of its submitter")
+	}
+	if !s.hasDeadline {
+		t.Error("TaskTimeout set no deadline")
+	}
+	_ = p.Close(context.Background())
+}
+
+func TestPoolSurvivesPanic(t *testing.T) {
+	var panics []string
+	OnPanic = func(where string) {
+		panics = append(panics, where)
+	}
+	defer func() {
+		OnPanic = nil
+	}()
+	p := NewPool("test", PoolOptions{Workers: 1})
+	_ = p.Submit(context.Background(), func(context.Context) {
+		panic("boom")
+	})
+	ran := make(chan struct{})
+	_ = p.Submit(context.Background(), func(context.Context) {
+		close(ran)
+	})
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("worker died with the panicking task")
+	}
+	_ = p.Close(context.Background())
+	if len(panics) != 1 || panics[0] != "Pool" {
+		t.Errorf("panics reported = %v", panics)
+	}
+}
@@ -3,11 +3,15 @@ package constant
 import "time"
 
 const (
-	BatchSize = 1000
-
 	FollowTypePerson = 1
 	FollowTypeTopic  = 2
 
+	// what a follower is notified of; the notify of a post is the level
+	// it is pushed at
+	NotifyAll        = 1
+	NotifyHighlights = 2
+	NotifyNone       = 3
+
 	GenderUndefined = 0
 	GenderBody      = 1
 	GenderGirl      = 2
@@ -0,0 +1,99 @@
+package lifecycle
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"socialservice/util/concurrent"
+	"socialservice/util/logger"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultShutdownTimeout = 15 * time.Second
+	// StopHookTimeout bounds each stop hook, so one that hangs neither
+	// holds up the rest nor leaves them a spent deadline.
+	StopHookTimeout = 5 * time.Second
+)
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// Manager holds the hooks that stop background loops and release
+// resources at shutdown.
+type Manager struct {
+	mu    sync.Mutex
+	hooks []hook
+}
+
+var std = New()
+
+func New() *Manager {
+	return &Manager{}
+}
+
+// Run starts fn on its own goroutine with a ctx that is cancelled at
+// shutdown, where the hook named name waits for fn to return. It is for
+// loops that live as long as the process.
+func (m *Manager) Run(name string, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	m.OnStop(name, func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	})
+	concurrent.Go(func() {
+		defer close(done)
+		fn(ctx)
+	})
+}
+
+// OnStop registers fn to run at shutdown. Hooks run in reverse order of
+// registration, so a resource is released before the ones it depends on.
+func (m *Manager) OnStop(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Shutdown runs every hook, each with StopHookTimeout of its own and the
+// values of ctx. It returns the first error met on the way.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	var rerr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hookCtx, cancel := context.WithTimeout(concurrent.Detach(ctx), StopHookTimeout)
+		err := hooks[i].fn(hookCtx)
+		cancel()
+		if err != nil {
+			logger.Error(ctx, "lifecycle stop hook", zap.String("hook", hooks[i].name), logger.Err(err))
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}
+	return rerr
+}
+
+func Run(name string, fn func(ctx context.Context)) {
+	std.Run(name, fn)
+}
+
+func OnStop(name string, fn func(ctx context.Context) error) {
+	std.OnStop(name, fn)
+}
+
+func Shutdown(ctx context.Context) error {
+	return std.Shutdown(ctx)
+}
@@ -0,0 +1,43 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestShutdownHooksOutliveDeadline(t *testing.T) {
+	m := New()
+	ran := false
+	m.OnStop("hook", func(ctx context.Context) error {
+		ran = true
+		if ctx.Err() != nil {
+			t.Errorf("hook got a done ctx: %v", ctx.Err())
+		}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Error("hook did not run")
+	}
+}
+
+func TestRunStopsLoop(t *testing.T) {
+	m := New()
+	stopped := false
+	m.Run("loop", func(ctx context.Context) {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		stopped = true
+	})
+	if err := m.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !stopped {
+		t.Error("Shutdown returned before the loop did")
+	}
+}
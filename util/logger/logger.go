@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"github.com/micro/go-micro/server"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"net/http"
+	"os"
+	"socialservice/conf"
+	"socialservice/util/tracing"
+	"time"
+)
+
+const (
+	DefaultMaxSize    = 100 // megabytes
+	DefaultMaxBackups = 10
+	DefaultMaxAge     = 7 // days
+
+	SampleTick = time.Second
+)
+
+type ctxKey struct{}
+
+var (
+	level = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	// log writes JSON to stderr until Init points it at the log files.
+	log = zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig()), zapcore.Lock(os.Stderr), level),
+		zap.AddCaller(), zap.AddCallerSkip(1))
+)
+
+// Init routes debug, info and warn-and-above entries to their own rotated
+// files. Each level can be sampled separately; levels without sampling
+// configured log everything.
+func Init(config conf.LogConf) error {
+	if config.Level != "" {
+		err := level.UnmarshalText([]byte(config.Level))
+		if err != nil {
+			return err
+		}
+	}
+	for lvl := range config.Sampling {
+		var l zapcore.Level
+		err := l.UnmarshalText([]byte(lvl))
+		if err != nil {
+			return fmt.Errorf("log sampling: %v", err)
+		}
+	}
+	debugW := rotate(config, config.Debug)
+	infoW := rotate(config, config.Info)
+	excW := rotate(config, config.Exc)
+	enc := zapcore.NewJSONEncoder(encoderConfig())
+	core := zapcore.NewTee(
+		levelCore(config, enc, debugW, zapcore.DebugLevel),
+		levelCore(config, enc, infoW, zapcore.InfoLevel),
+		levelCore(config, enc, excW, zapcore.WarnLevel),
+		levelCore(config, enc, excW, zapcore.ErrorLevel, zapcore.DPanicLevel, zapcore.PanicLevel, zapcore.FatalLevel),
+	)
+	log = zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	return nil
+}
+
+func rotate(config conf.LogConf, path string) zapcore.WriteSyncer {
+	maxSize, maxBackups, maxAge := config.MaxSize, config.MaxBackups, config.MaxAge
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = DefaultMaxBackups
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackups,
+		MaxAge:     maxAge,
+		LocalTime:  true,
+	})
+}
+
+// levelCore writes entries of exactly the given levels to w, sampled with
+// the settings of the first one.
+func levelCore(config conf.LogConf, enc zapcore.Encoder, w zapcore.WriteSyncer, lvls ...zapcore.Level) zapcore.Core {
+	enabler := zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+		if !level.Enabled(l) {
+			return false
+		}
+		for _, lvl := range lvls {
+			if l == lvl {
+				return true
+			}
+		}
+		return false
+	})
+	core := zapcore.NewCore(enc, w, enabler)
+	if s, ok := config.Sampling[lvls[0].String()]; ok && s.Thereafter > 0 {
+		core = zapcore.NewSampler(core, SampleTick, s.Initial, s.Thereafter)
+	}
+	return core
+}
+
+func encoderConfig() zapcore.EncoderConfig {
+	ec := zap.NewProductionEncoderConfig()
+	ec.TimeKey = "time"
+	ec.EncodeTime = zapcore.ISO8601TimeEncoder
+	return ec
+}
+
+// LevelHandler reports the current level on GET and changes it on PUT with
+// a body like {"level":"debug"}.
+func LevelHandler() http.Handler {
+	return level
+}
+
+func Sync() error {
+	return log.Sync()
+}
+
+// With returns a ctx whose log entries all carry fields.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	prev, _ := ctx.Value(ctxKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(prev)+len(fields))
+	merged = append(merged, prev...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+func Debug(ctx context.Context, msg string, fields ...zap.Field) {
+	if ce := log.Check(zapcore.DebugLevel, msg); ce != nil {
+		ce.Write(withContext(ctx, fields)...)
+	}
+}
+
+func Info(ctx context.Context, msg string, fields ...zap.Field) {
+	if ce := log.Check(zapcore.InfoLevel, msg); ce != nil {
+		ce.Write(withContext(ctx, fields)...)
+	}
+}
+
+func Warn(ctx context.Context, msg string, fields ...zap.Field) {
+	if ce := log.Check(zapcore.WarnLevel, msg); ce != nil {
+		ce.Write(withContext(ctx, fields)...)
+	}
+}
+
+func Error(ctx context.Context, msg string, fields ...zap.Field) {
+	if ce := log.Check(zapcore.ErrorLevel, msg); ce != nil {
+		ce.Write(withContext(ctx, fields)...)
+	}
+}
+
+func withContext(ctx context.Context, fields []zap.Field) []zap.Field {
+	if ctx == nil {
+		return fields
+	}
+	if ctxFields, ok := ctx.Value(ctxKey{}).([]zap.Field); ok {
+		fields = append(ctxFields[:len(ctxFields):len(ctxFields)], fields...)
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		fields = append(fields, zap.String("trace_id", traceID))
+	}
+	return fields
+}
+
+func UID(uid int64) zap.Field {
+	return zap.Int64("uid", uid)
+}
+
+func TargetID(id int64) zap.Field {
+	return zap.Int64("target_id", id)
+}
+
+func Err(err error) zap.Field {
+	return zap.Error(err)
+}
+
+// HandlerWrapper tags every entry logged while serving a request with the
+// rpc name.
+func HandlerWrapper(fn server.HandlerFunc) server.HandlerFunc {
+	return func(ctx context.Context, req server.Request, rsp interface{}) error {
+		return fn(With(ctx, zap.String("rpc", req.Endpoint())), req, rsp)
+	}
+}
+
+// UnaryServerInterceptor is HandlerWrapper for the native gRPC server.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return handler(With(ctx, zap.String("rpc", tracing.Endpoint(info.FullMethod))), req)
+}
+
+// StreamServerInterceptor is HandlerWrapper for native gRPC streams.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := With(ss.Context(), zap.String("rpc", tracing.Endpoint(info.FullMethod)))
+	return handler(srv, tracing.WrapServerStream(ss, ctx))
+}
@@ -0,0 +1,110 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type entry struct {
+	key    string
+	value  interface{}
+	expire time.Time
+}
+
+type Stats struct {
+	Len       int
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// Cache is a size-bounded LRU whose entries also expire after a fixed TTL.
+type Cache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits      uint64
+	misses    uint64
+	evictions uint64
+}
+
+func New(size int, ttl time.Duration) *Cache {
+	return &Cache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element, size),
+	}
+}
+
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ele, ok := c.items[key]
+	if !ok {
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	e := ele.Value.(*entry)
+	if time.Now().After(e.expire) {
+		c.removeElement(ele)
+		atomic.AddUint64(&c.misses, 1)
+		return nil, false
+	}
+	c.ll.MoveToFront(ele)
+	atomic.AddUint64(&c.hits, 1)
+	return e.value, true
+}
+
+func (c *Cache) Set(key string, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	expire := time.Now().Add(c.ttl)
+	if ele, ok := c.items[key]; ok {
+		e := ele.Value.(*entry)
+		e.value = value
+		e.expire = expire
+		c.ll.MoveToFront(ele)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry{key: key, value: value, expire: expire})
+	for c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+		atomic.AddUint64(&c.evictions, 1)
+	}
+}
+
+func (c *Cache) Remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if ele, ok := c.items[key]; ok {
+			c.removeElement(ele)
+		}
+	}
+}
+
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache) Stats() Stats {
+	return Stats{
+		Len:       c.Len(),
+		Hits:      atomic.LoadUint64(&c.hits),
+		Misses:    atomic.LoadUint64(&c.misses),
+		Evictions: atomic.LoadUint64(&c.evictions),
+	}
+}
+
+func (c *Cache) removeElement(ele *list.Element) {
+	c.ll.Remove(ele)
+	delete(c.items, ele.Value.(*entry).key)
+}
@@ -0,0 +1,172 @@
+package metrics
+
+import (
+	"context"
+	"github.com/micro/go-micro/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"net/http"
+	"socialservice/util/lru"
+	"socialservice/util/tracing"
+	"time"
+)
+
+const Namespace = "social_service"
+
+var (
+	RPCLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "RPC handler latency by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+	RPCErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "rpc_errors_total",
+		Help:      "RPC handler errors by endpoint.",
+	}, []string{"endpoint"})
+	CacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "cache_requests_total",
+		Help:      "Cache lookups by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+	DBLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "db_duration_seconds",
+		Help:      "MySQL query latency by function.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"query"})
+	RedisFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "redis_failures_total",
+		Help:      "Failed Redis pipelines and scripts by operation.",
+	}, []string{"op"})
+	Panics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "goroutine_panics_total",
+		Help:      "Panics recovered in background goroutines by helper.",
+	}, []string{"func"})
+	PoolRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "pool_rejected_total",
+		Help:      "Tasks a worker pool turned away because its queue was full.",
+	}, []string{"pool"})
+)
+
+func init() {
+	prometheus.MustRegister(RPCLatency, RPCErrors, CacheRequests, DBLatency, RedisFailures, Panics, PoolRejections)
+}
+
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// HandlerWrapper records latency and errors of every go-micro handler call.
+func HandlerWrapper(fn server.HandlerFunc) server.HandlerFunc {
+	return func(ctx context.Context, req server.Request, rsp interface{}) error {
+		start := time.Now()
+		err := fn(ctx, req, rsp)
+		ObserveRPC(req.Endpoint(), start, err)
+		return err
+	}
+}
+
+// UnaryServerInterceptor is HandlerWrapper for the native gRPC server.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	rsp, err := handler(ctx, req)
+	ObserveRPC(tracing.Endpoint(info.FullMethod), start, err)
+	return rsp, err
+}
+
+// StreamServerInterceptor is HandlerWrapper for native gRPC streams.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	ObserveRPC(tracing.Endpoint(info.FullMethod), start, err)
+	return err
+}
+
+// ObserveRPC records one call of endpoint that began at start, for entry
+// points that do not go through go-micro, such as the HTTP gateway.
+func ObserveRPC(endpoint string, start time.Time, err error) {
+	RPCLatency.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(endpoint).Inc()
+	}
+}
+
+func CacheHit(cache string) {
+	CacheRequests.WithLabelValues(cache, "hit").Inc()
+}
+
+func CacheMiss(cache string) {
+	CacheRequests.WithLabelValues(cache, "miss").Inc()
+}
+
+// ObserveDB is meant to be deferred at the top of a query function.
+func ObserveDB(query string, start time.Time) {
+	DBLatency.WithLabelValues(query).Observe(time.Since(start).Seconds())
+}
+
+func RedisFailure(op string) {
+	RedisFailures.WithLabelValues(op).Inc()
+}
+
+func Panic(fn string) {
+	Panics.WithLabelValues(fn).Inc()
+}
+
+func PoolRejected(pool string) {
+	PoolRejections.WithLabelValues(pool).Inc()
+}
+
+// RegisterPool exports the queue length of a worker pool.
+func RegisterPool(name string, queued func() float64) {
+	prometheus.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   Namespace,
+		Name:        "pool_queued_tasks",
+		Help:        "Tasks waiting in a worker pool queue.",
+		ConstLabels: prometheus.Labels{"pool": name},
+	}, queued))
+}
+
+// RegisterLRU exports the size and hit/miss/eviction counters of c.
+func RegisterLRU(name string, c *lru.Cache) {
+	labels := prometheus.Labels{"cache": name}
+	prometheus.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace:   Namespace,
+			Name:        "lru_entries",
+			Help:        "Entries held by an in-process LRU.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(c.Len())
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "lru_hits_total",
+			Help:        "Hits of an in-process LRU.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(c.Stats().Hits)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "lru_misses_total",
+			Help:        "Misses of an in-process LRU.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(c.Stats().Misses)
+		}),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace:   Namespace,
+			Name:        "lru_evictions_total",
+			Help:        "Evictions of an in-process LRU.",
+			ConstLabels: labels,
+		}, func() float64 {
+			return float64(c.Stats().Evictions)
+		}),
+	)
+}
@@ -0,0 +1,261 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OTLPTimeout bounds one export request.
+const OTLPTimeout = 10 * time.Second
+
+// otlpExporter posts spans to an OpenTelemetry collector as OTLP/HTTP JSON.
+// The otlptrace exporters of this otel release need a newer grpc than
+// go-micro can run with, and the JSON encoding needs no generated code.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+}
+
+// newOTLPExporter sends to the collector at endpoint, such as
+// http://otel-collector:4318.
+func newOTLPExporter(endpoint string) *otlpExporter {
+	return &otlpExporter{
+		url:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		client: &http.Client{Timeout: OTLPTimeout},
+	}
+}
+
+func (e *otlpExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(otlpRequest(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rsp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	msg, _ := ioutil.ReadAll(io.LimitReader(rsp.Body, 512))
+	if rsp.StatusCode/100 != 2 {
+		return fmt.Errorf("otlp export: %v: %s", rsp.Status, msg)
+	}
+	return nil
+}
+
+func (e *otlpExporter) Shutdown(context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+// The types below follow the JSON mapping of the OTLP protobufs: 64-bit
+// integers are strings and trace and span ids are hex.
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name,omitempty"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Events            []otlpEvent    `json:"events,omitempty"`
+		Links             []otlpLink     `json:"links,omitempty"`
+		Status            otlpStatus     `json:"status"`
+	}
+	otlpEvent struct {
+		TimeUnixNano string         `json:"timeUnixNano"`
+		Name         string         `json:"name"`
+		Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpLink struct {
+		TraceID    string         `json:"traceId"`
+		SpanID     string         `json:"spanId"`
+		Attributes []otlpKeyValue `json:"attributes,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code,omitempty"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string         `json:"stringValue,omitempty"`
+		BoolValue   *bool           `json:"boolValue,omitempty"`
+		IntValue    *string         `json:"intValue,omitempty"`
+		DoubleValue *float64        `json:"doubleValue,omitempty"`
+		ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	}
+	otlpArrayValue struct {
+		Values []otlpValue `json:"values"`
+	}
+)
+
+// OTLP status codes, which are not numbered like codes.Code.
+const (
+	otlpStatusOK    = 1
+	otlpStatusError = 2
+)
+
+// otlpRequest groups spans by resource and then by instrumentation library.
+func otlpRequest(spans []sdktrace.ReadOnlySpan) otlpTraces {
+	var req otlpTraces
+	resources := map[*resource.Resource]int{}
+	for _, s := range spans {
+		r, ok := resources[s.Resource()]
+		if !ok {
+			r = len(req.ResourceSpans)
+			resources[s.Resource()] = r
+			req.ResourceSpans = append(req.ResourceSpans, otlpResourceSpans{
+				Resource: otlpResource{Attributes: otlpAttributes(s.Resource().Attributes())},
+			})
+		}
+		rs := &req.ResourceSpans[r]
+		lib := s.InstrumentationLibrary()
+		scope := -1
+		for i, ss := range rs.ScopeSpans {
+			if ss.Scope.Name == lib.Name && ss.Scope.Version == lib.Version {
+				scope = i
+				break
+			}
+		}
+		if scope < 0 {
+			scope = len(rs.ScopeSpans)
+			rs.ScopeSpans = append(rs.ScopeSpans, otlpScopeSpans{Scope: otlpScope{Name: lib.Name, Version: lib.Version}})
+		}
+		rs.ScopeSpans[scope].Spans = append(rs.ScopeSpans[scope].Spans, otlpSpanOf(s))
+	}
+	return req
+}
+
+func otlpSpanOf(s sdktrace.ReadOnlySpan) otlpSpan {
+	span := otlpSpan{
+		TraceID:           s.SpanContext().TraceID().String(),
+		SpanID:            s.SpanContext().SpanID().String(),
+		Name:              s.Name(),
+		Kind:              int(s.SpanKind()),
+		StartTimeUnixNano: otlpTime(s.StartTime()),
+		EndTimeUnixNano:   otlpTime(s.EndTime()),
+		Attributes:        otlpAttributes(s.Attributes()),
+	}
+	if s.Parent().HasSpanID() {
+		span.ParentSpanID = s.Parent().SpanID().String()
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, otlpEvent{
+			TimeUnixNano: otlpTime(e.Time),
+			Name:         e.Name,
+			Attributes:   otlpAttributes(e.Attributes),
+		})
+	}
+	for _, l := range s.Links() {
+		span.Links = append(span.Links, otlpLink{
+			TraceID:    l.SpanContext.TraceID().String(),
+			SpanID:     l.SpanContext.SpanID().String(),
+			Attributes: otlpAttributes(l.Attributes),
+		})
+	}
+	switch s.Status().Code {
+	case codes.Ok:
+		span.Status.Code = otlpStatusOK
+	case codes.Error:
+		span.Status = otlpStatus{Code: otlpStatusError, Message: s.Status().Description}
+	}
+	return span
+}
+
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+func otlpAttributes(attrs []attribute.KeyValue) []otlpKeyValue {
+	if len(attrs) == 0 {
+		return nil
+	}
+	kvs := make([]otlpKeyValue, 0, len(attrs))
+	for _, kv := range attrs {
+		kvs = append(kvs, otlpKeyValue{Key: string(kv.Key), Value: otlpValueOf(kv.Value)})
+	}
+	return kvs
+}
+
+func otlpValueOf(v attribute.Value) otlpValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var arr otlpArrayValue
+		for _, b := range v.AsBoolSlice() {
+			arr.Values = append(arr.Values, otlpValueOf(attribute.BoolValue(b)))
+		}
+		return otlpValue{ArrayValue: &arr}
+	case attribute.INT64SLICE:
+		var arr otlpArrayValue
+		for _, i := range v.AsInt64Slice() {
+			arr.Values = append(arr.Values, otlpValueOf(attribute.Int64Value(i)))
+		}
+		return otlpValue{ArrayValue: &arr}
+	case attribute.FLOAT64SLICE:
+		var arr otlpArrayValue
+		for _, f := range v.AsFloat64Slice() {
+			arr.Values = append(arr.Values, otlpValueOf(attribute.Float64Value(f)))
+		}
+		return otlpValue{ArrayValue: &arr}
+	case attribute.STRINGSLICE:
+		var arr otlpArrayValue
+		for _, s := range v.AsStringSlice() {
+			arr.Values = append(arr.Values, otlpValueOf(attribute.StringValue(s)))
+		}
+		return otlpValue{ArrayValue: &arr}
+	default:
+		s := v.Emit()
+		return otlpValue{StringValue: &s}
+	}
+}
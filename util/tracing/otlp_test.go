@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	got := make(chan otlpTraces, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("got %v %v with %q", r.Method, r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var req otlpTraces
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		got <- req
+	}))
+	defer collector.Close()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	ctx, parent := tp.Tracer(TracerName).Start(context.Background(), "parent")
+	_, child := tp.Tracer(TracerName).Start(ctx, "child",
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.Int64("uid", 7), attribute.StringSlice("keys", []string{"a", "b"})),
+	)
+	End(child, errors.New("boom"))
+	parent.End()
+
+	exp := newOTLPExporter(collector.URL + "/")
+	if err := exp.ExportSpans(context.Background(), rec.Ended()); err != nil {
+		t.Fatal(err)
+	}
+	req := <-got
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("got %+v, want one resource with one scope", req)
+	}
+	scope := req.ResourceSpans[0].ScopeSpans[0]
+	if scope.Scope.Name != TracerName || len(scope.Spans) != 2 {
+		t.Fatalf("got scope %+v", scope)
+	}
+	span := scope.Spans[0]
+	if span.Name != "child" || span.Kind != int(trace.SpanKindServer) {
+		t.Errorf("got span %v of kind %v", span.Name, span.Kind)
+	}
+	if span.TraceID != parent.SpanContext().TraceID().String() || span.ParentSpanID != parent.SpanContext().SpanID().String() {
+		t.Errorf("got trace %v parent %v, want the parent's", span.TraceID, span.ParentSpanID)
+	}
+	if span.Status.Code != otlpStatusError || span.Status.Message != "boom" {
+		t.Errorf("got status %+v", span.Status)
+	}
+	if len(span.Attributes) != 2 || *span.Attributes[0].Value.IntValue != "7" || len(span.Attributes[1].Value.ArrayValue.Values) != 2 {
+		t.Errorf("got attributes %+v", span.Attributes)
+	}
+	if len(span.Events) != 1 || span.Events[0].Name != "exception" {
+		t.Errorf("got events %+v, want the recorded error", span.Events)
+	}
+	if scope.Spans[1].ParentSpanID != "" {
+		t.Errorf("root span has parent %v", scope.Spans[1].ParentSpanID)
+	}
+}
+
+func TestOTLPExporterError(t *testing.T) {
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "overloaded", http.StatusServiceUnavailable)
+	}))
+	defer collector.Close()
+
+	rec := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	_, span := tp.Tracer(TracerName).Start(context.Background(), "span")
+	span.End()
+	if err := newOTLPExporter(collector.URL).ExportSpans(context.Background(), rec.Ended()); err == nil {
+		t.Error("a 503 from the collector was not reported")
+	}
+}
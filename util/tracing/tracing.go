@@ -0,0 +1,270 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-redis/redis/v8"
+	"github.com/micro/go-micro/metadata"
+	"github.com/micro/go-micro/server"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	grpcmd "google.golang.org/grpc/metadata"
+	"net/http"
+	"strings"
+)
+
+const (
+	TracerName = "socialservice"
+
+	ExporterStdout = "stdout"
+	ExporterJaeger = "jaeger"
+	ExporterOTLP   = "otlp"
+
+	// DefaultRatio samples every new trace when no ratio is configured.
+	DefaultRatio = 1.0
+)
+
+// Init installs the global tracer provider. An empty exporter leaves
+// tracing disabled, and a zero ratio samples at DefaultRatio. The returned
+// func flushes and stops the exporter.
+func Init(serviceName, exporter, endpoint string, ratio float64) (func(context.Context) error, error) {
+	var (
+		exp sdktrace.SpanExporter
+		err error
+	)
+	switch exporter {
+	case "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterJaeger:
+		exp, err = jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(endpoint)))
+	case ExporterOTLP:
+		exp = newOTLPExporter(endpoint)
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ratio == 0 {
+		ratio = DefaultRatio
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(serviceName))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(TracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the hex trace id carried by ctx, or "" outside a trace.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
+
+// metadataCarrier adapts go-micro metadata, whose keys may arrive
+// canonicalised by the transport, to a TextMapCarrier.
+type metadataCarrier metadata.Metadata
+
+func (c metadataCarrier) Get(key string) string {
+	for k, v := range c {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// HandlerWrapper continues the caller's trace from the request metadata
+// and wraps every go-micro handler call in a server span.
+func HandlerWrapper(fn server.HandlerFunc) server.HandlerFunc {
+	return func(ctx context.Context, req server.Request, rsp interface{}) error {
+		if md, ok := metadata.FromContext(ctx); ok {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+		}
+		ctx, span := otel.Tracer(TracerName).Start(ctx, req.Endpoint(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.RPCSystemKey.String("go-micro"),
+				semconv.RPCServiceKey.String(req.Service()),
+				semconv.RPCMethodKey.String(req.Method()),
+			),
+		)
+		err := fn(ctx, req, rsp)
+		End(span, err)
+		return err
+	}
+}
+
+// grpcCarrier adapts incoming gRPC metadata to the propagator.
+type grpcCarrier grpcmd.MD
+
+func (c grpcCarrier) Get(key string) string {
+	vals := grpcmd.MD(c).Get(key)
+	if len(vals) == 0 {
+		return ""
+	}
+	return vals[0]
+}
+
+func (c grpcCarrier) Set(key, value string) {
+	grpcmd.MD(c).Set(key, value)
+}
+
+func (c grpcCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func startGRPC(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	if md, ok := grpcmd.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, grpcCarrier(md))
+	}
+	service, method := splitMethod(fullMethod)
+	return otel.Tracer(TracerName).Start(ctx, Endpoint(fullMethod),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("grpc"),
+			semconv.RPCServiceKey.String(service),
+			semconv.RPCMethodKey.String(method),
+		),
+	)
+}
+
+// Endpoint turns a gRPC method such as /social.SocialServer/Follow into
+// the go-micro endpoint SocialServer.Follow, so both transports report
+// calls alike.
+func Endpoint(fullMethod string) string {
+	fullMethod = fullMethod[strings.LastIndex(fullMethod, ".")+1:]
+	return strings.Replace(fullMethod, "/", ".", 1)
+}
+
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// UnaryServerInterceptor is HandlerWrapper for the native gRPC server.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	ctx, span := startGRPC(ctx, info.FullMethod)
+	rsp, err := handler(ctx, req)
+	End(span, err)
+	return rsp, err
+}
+
+// StreamServerInterceptor is HandlerWrapper for native gRPC streams.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startGRPC(ss.Context(), info.FullMethod)
+	err := handler(srv, WrapServerStream(ss, ctx))
+	End(span, err)
+	return err
+}
+
+// WrapServerStream returns ss with its context replaced by ctx, for stream
+// interceptors that add to the context.
+func WrapServerStream(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	return &serverStream{ServerStream: ss, ctx: ctx}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// StartHTTP continues the caller's trace from the request headers and
+// starts a server span for an HTTP request.
+func StartHTTP(r *http.Request, name string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+	return otel.Tracer(TracerName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest(TracerName, name, r)...),
+	)
+}
+
+// RedisHook puts every Redis command and pipeline in its own span.
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	ctx, _ = Start(ctx, "redis "+cmd.Name(), semconv.DBSystemRedis)
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	endRedis(trace.SpanFromContext(ctx), cmd.Err())
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	ctx, _ = Start(ctx, "redis pipeline", semconv.DBSystemRedis, attribute.Int("db.redis.num_cmd", len(cmds)))
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	endRedis(trace.SpanFromContext(ctx), err)
+	return nil
+}
+
+func endRedis(span trace.Span, err error) {
+	if err == redis.Nil {
+		err = nil
+	}
+	End(span, err)
+}